@@ -26,6 +26,7 @@ type GameEventType string
 const (
 	EVENT_TYPE_FRAME    GameEventType = "frame"
 	EVENT_TYPE_GAME_END GameEventType = "game_end"
+	EVENT_TYPE_PLAYBACK GameEventType = "playback"
 )
 
 // Top-level JSON structure sent in each websocket frame.
@@ -70,3 +71,29 @@ type Death struct {
 	Turn         int    `json:"Turn"`
 	EliminatedBy string `json:"EliminatedBy"`
 }
+
+// Actions that can be sent to control the playback of a game, either over
+// the websocket stream or to the /games/:id/control endpoint.
+type PlaybackAction string
+
+const (
+	PLAYBACK_ACTION_PAUSE  PlaybackAction = "pause"
+	PLAYBACK_ACTION_RESUME PlaybackAction = "resume"
+	PLAYBACK_ACTION_STEP   PlaybackAction = "step"
+	PLAYBACK_ACTION_BACK   PlaybackAction = "back"
+	PLAYBACK_ACTION_JUMP   PlaybackAction = "jump"
+	PLAYBACK_ACTION_SPEED  PlaybackAction = "speed"
+)
+
+type PlaybackControl struct {
+	Action PlaybackAction `json:"Action"`
+	Turn   *int           `json:"Turn,omitempty"` // the turn to jump to, or for other actions the turn the client is showing
+	Speed  float64        `json:"Speed"`          // used by speed, as a multiple of the normal speed
+}
+
+// The playback state shared by every client watching a game, sent as the data of playback events.
+type PlaybackState struct {
+	Paused bool    `json:"Paused"`
+	Turn   int     `json:"Turn"` // the turn shown while paused, or -1 to carry on from the current turn
+	Speed  float64 `json:"Speed"`
+}
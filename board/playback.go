@@ -0,0 +1,159 @@
+package board
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	log "github.com/spf13/jwalterweatherman"
+)
+
+func defaultPlaybackState() PlaybackState {
+	return PlaybackState{Turn: -1, Speed: 1}
+}
+
+// Apply a playback control to the game, and notify every client of the new state.
+func (hub *gameHub) applyControl(control PlaybackControl) (PlaybackState, error) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	// Clients play through the frames at their own pace, so unless the game
+	// is paused on a turn the client's turn is used.
+	playback := hub.playback
+	currentTurn := playback.Turn
+	if currentTurn < 0 && control.Turn != nil {
+		currentTurn = *control.Turn
+	}
+	if currentTurn < 0 || currentTurn > hub.lastTurn {
+		currentTurn = hub.lastTurn
+	}
+
+	switch control.Action {
+	case PLAYBACK_ACTION_PAUSE:
+		playback.Paused = true
+		playback.Turn = currentTurn
+	case PLAYBACK_ACTION_RESUME:
+		// Clients were all showing the paused turn, so they carry on from there
+		playback.Paused = false
+		playback.Turn = -1
+	case PLAYBACK_ACTION_STEP:
+		playback.Paused = true
+		if currentTurn < hub.lastTurn {
+			playback.Turn = currentTurn + 1
+		} else if !hub.closed {
+			// Let a paused live game play one more turn, which will then be shown
+			hub.pendingSteps++
+			playback.Turn = -1
+		}
+	case PLAYBACK_ACTION_BACK:
+		playback.Paused = true
+		playback.Turn = currentTurn - 1
+		if playback.Turn < hub.firstTurn {
+			playback.Turn = hub.firstTurn
+		}
+	case PLAYBACK_ACTION_JUMP:
+		if control.Turn == nil {
+			return hub.playback, fmt.Errorf("no turn to jump to")
+		}
+		if *control.Turn < hub.firstTurn || *control.Turn > hub.lastTurn {
+			return hub.playback, fmt.Errorf("turn %d hasn't been played", *control.Turn)
+		}
+		playback.Paused = true
+		playback.Turn = *control.Turn
+	case PLAYBACK_ACTION_SPEED:
+		if control.Speed <= 0 {
+			return hub.playback, fmt.Errorf("invalid speed %v", control.Speed)
+		}
+		playback.Speed = control.Speed
+	default:
+		return hub.playback, fmt.Errorf("unknown action %#v", control.Action)
+	}
+
+	hub.playback = playback
+	hub.playbackVersion++
+	hub.notify()
+	return playback, nil
+}
+
+func (hub *gameHub) playbackState() PlaybackState {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	return hub.playback
+}
+
+// Block while the game is paused, unless a client has asked to step forward,
+// the game is finished, or the context is cancelled.
+func (hub *gameHub) waitWhilePaused(ctx context.Context) error {
+	for {
+		hub.mutex.Lock()
+		if !hub.playback.Paused || hub.closed {
+			hub.mutex.Unlock()
+			return nil
+		}
+		if hub.pendingSteps > 0 {
+			hub.pendingSteps--
+			hub.mutex.Unlock()
+			return nil
+		}
+		updated := hub.updated
+		hub.mutex.Unlock()
+
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Handle the /games/:id/control endpoint. GET returns the playback state and
+// POST applies a PlaybackControl to it.
+func (server *BoardServer) handleControl(w http.ResponseWriter, r *http.Request, hub *gameHub) {
+	var playback PlaybackState
+	switch r.Method {
+	case http.MethodGet:
+		playback = hub.playbackState()
+	case http.MethodPost:
+		var control PlaybackControl
+		if err := json.NewDecoder(r.Body).Decode(&control); err != nil {
+			http.Error(w, fmt.Sprintf("invalid control: %v", err), http.StatusBadRequest)
+			return
+		}
+		var err error
+		playback, err = hub.applyControl(control)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(playback); err != nil {
+		log.ERROR.Printf("Unable to serialize playback state: %v", err)
+	}
+}
+
+// Control the playback of a game, as if the control was sent by a client.
+func (server *BoardServer) ControlPlayback(gameID string, control PlaybackControl) (PlaybackState, error) {
+	hub := server.getGame(gameID)
+	if hub == nil {
+		return PlaybackState{}, fmt.Errorf("unknown game %s", gameID)
+	}
+	return hub.applyControl(control)
+}
+
+// Block while a game is paused by one of its clients. Live games call this
+// before each turn, so that pausing the viewer also pauses the game.
+func (server *BoardServer) WaitWhilePaused(ctx context.Context, gameID string) error {
+	hub := server.getGame(gameID)
+	if hub == nil {
+		return nil
+	}
+	return hub.waitWhilePaused(ctx)
+}
@@ -0,0 +1,115 @@
+package board
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestPlaybackControls(t *testing.T) {
+	server := NewBoardServer(Game{ID: "GAME_ID"})
+	for turn := 0; turn <= 5; turn++ {
+		server.SendEvent(GameEvent{EventType: EVENT_TYPE_FRAME, Data: GameFrame{Turn: turn}})
+	}
+
+	tests := []struct {
+		control  PlaybackControl
+		expected PlaybackState
+	}{
+		{PlaybackControl{Action: PLAYBACK_ACTION_PAUSE, Turn: intPtr(3)}, PlaybackState{Paused: true, Turn: 3, Speed: 1}},
+		{PlaybackControl{Action: PLAYBACK_ACTION_STEP}, PlaybackState{Paused: true, Turn: 4, Speed: 1}},
+		{PlaybackControl{Action: PLAYBACK_ACTION_BACK}, PlaybackState{Paused: true, Turn: 3, Speed: 1}},
+		{PlaybackControl{Action: PLAYBACK_ACTION_JUMP, Turn: intPtr(0)}, PlaybackState{Paused: true, Turn: 0, Speed: 1}},
+		{PlaybackControl{Action: PLAYBACK_ACTION_BACK}, PlaybackState{Paused: true, Turn: 0, Speed: 1}},
+		{PlaybackControl{Action: PLAYBACK_ACTION_SPEED, Speed: 2}, PlaybackState{Paused: true, Turn: 0, Speed: 2}},
+		{PlaybackControl{Action: PLAYBACK_ACTION_RESUME}, PlaybackState{Paused: false, Turn: -1, Speed: 2}},
+		{PlaybackControl{Action: PLAYBACK_ACTION_PAUSE}, PlaybackState{Paused: true, Turn: 5, Speed: 2}},
+	}
+	for _, test := range tests {
+		playback, err := server.ControlPlayback("GAME_ID", test.control)
+		require.NoError(t, err)
+		require.Equal(t, test.expected, playback, test.control.Action)
+	}
+
+	_, err := server.ControlPlayback("GAME_ID", PlaybackControl{Action: PLAYBACK_ACTION_JUMP, Turn: intPtr(6)})
+	require.Error(t, err)
+	_, err = server.ControlPlayback("GAME_ID", PlaybackControl{Action: PLAYBACK_ACTION_SPEED})
+	require.Error(t, err)
+	_, err = server.ControlPlayback("GAME_ID", PlaybackControl{Action: "rewind"})
+	require.Error(t, err)
+}
+
+func TestWaitWhilePaused(t *testing.T) {
+	server := NewBoardServer(Game{ID: "GAME_ID"})
+	server.SendEvent(GameEvent{EventType: EVENT_TYPE_FRAME, Data: GameFrame{Turn: 0}})
+	require.NoError(t, server.WaitWhilePaused(context.Background(), "GAME_ID"))
+
+	_, err := server.ControlPlayback("GAME_ID", PlaybackControl{Action: PLAYBACK_ACTION_PAUSE})
+	require.NoError(t, err)
+
+	// stepping forward on the latest turn lets a live game play one more turn
+	_, err = server.ControlPlayback("GAME_ID", PlaybackControl{Action: PLAYBACK_ACTION_STEP})
+	require.NoError(t, err)
+	require.NoError(t, server.WaitWhilePaused(context.Background(), "GAME_ID"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, server.WaitWhilePaused(ctx, "GAME_ID"), context.DeadlineExceeded)
+
+	waited := make(chan error)
+	go func() { waited <- server.WaitWhilePaused(context.Background(), "GAME_ID") }()
+	_, err = server.ControlPlayback("GAME_ID", PlaybackControl{Action: PLAYBACK_ACTION_RESUME})
+	require.NoError(t, err)
+	require.NoError(t, <-waited)
+}
+
+func TestPlaybackEndpoints(t *testing.T) {
+	server := NewBoardServer(Game{ID: "GAME_ID"})
+	server.SendEvent(GameEvent{EventType: EVENT_TYPE_FRAME, Data: GameFrame{Turn: 0}})
+
+	httpServer := httptest.NewServer(server.httpServer.Handler)
+	defer httpServer.Close()
+
+	body, _ := json.Marshal(PlaybackControl{Action: PLAYBACK_ACTION_PAUSE})
+	response, err := http.Post(httpServer.URL+"/games/GAME_ID/control", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	var playback PlaybackState
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&playback))
+	require.Equal(t, PlaybackState{Paused: true, Turn: 0, Speed: 1}, playback)
+
+	response, err = http.Post(httpServer.URL+"/games/GAME_ID/control", "application/json", strings.NewReader(`{"Action":"rewind"}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	// clients are sent the current playback state, and can change it
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/games/GAME_ID/events", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	var event GameEvent
+	require.NoError(t, ws.ReadJSON(&event))
+	require.Equal(t, EVENT_TYPE_PLAYBACK, event.EventType)
+	require.NoError(t, ws.ReadJSON(&event))
+	require.Equal(t, EVENT_TYPE_FRAME, event.EventType)
+
+	require.NoError(t, ws.WriteJSON(PlaybackControl{Action: PLAYBACK_ACTION_RESUME}))
+	require.NoError(t, ws.ReadJSON(&event))
+	require.Equal(t, EVENT_TYPE_PLAYBACK, event.EventType)
+	require.Equal(t, map[string]interface{}{"Paused": false, "Turn": float64(-1), "Speed": float64(1)}, event.Data)
+
+	response, err = http.Get(httpServer.URL + "/games/GAME_ID/control")
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&playback))
+	require.Equal(t, PlaybackState{Paused: false, Turn: -1, Speed: 1}, playback)
+}
@@ -2,54 +2,157 @@ package board
 
 import (
 	"context"
+	"embed"
 	"encoding/json"
 	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/rs/cors"
 	log "github.com/spf13/jwalterweatherman"
 )
 
-// A minimal server capable of handling the requests from a single browser client running the board viewer.
+// A minimal server capable of handling the requests from browser clients running the board viewer.
+// Any number of games can be registered, and each game can be watched by any number of clients.
 type BoardServer struct {
-	game   Game
-	events chan GameEvent // channel for sending events from the game runner to the browser client
-	done   chan bool      // channel for signalling (via closing) that all events have been sent to the browser client
+	gameID string // the game that SendEvent sends to
+
+	mutex sync.Mutex
+	games map[string]*gameHub
 
 	httpServer *http.Server
+
+	// How long Shutdown waits for a client to connect to a game nobody has
+	// watched yet, such as a browser that's still opening
+	connectTimeout time.Duration
+}
+
+// The default time Shutdown waits for a client to connect to each game.
+const defaultConnectTimeout = 10 * time.Second
+
+// The events sent for a single game, and the clients watching it.
+type gameHub struct {
+	game Game
+
+	mutex   sync.Mutex
+	history []GameEvent   // every event sent so far, so that clients joining late can catch up
+	updated chan struct{} // closed (and replaced) whenever an event is added or the game is finished
+	closed  bool          // set once no more events will be sent
+
+	firstTurn       int // the first and last turns sent as frames
+	lastTurn        int
+	playback        PlaybackState
+	playbackVersion int // incremented whenever the playback state changes, so that clients know to send it
+	pendingSteps    int // turns a paused live game has been asked to step forward
+
+	connected     chan bool // closed once a client has connected to receive events
+	connectedOnce sync.Once
+	delivered     chan bool // closed once the first client to finish has received every event or disconnected
+	deliveredOnce sync.Once
 }
 
+// A minimal board viewer served at /viewer/, for when the Battlesnake game board can't be reached.
+//
+//go:embed viewer
+var viewerFiles embed.FS
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true
 	},
 }
 
+// Create a board server for a single game. Further games can be registered with AddGame.
 func NewBoardServer(game Game) *BoardServer {
 	mux := http.NewServeMux()
 
 	server := &BoardServer{
-		game:   game,
-		events: make(chan GameEvent, 1000), // buffered channel to allow game to run ahead of browser client
-		done:   make(chan bool),
+		gameID: game.ID,
+		games:  map[string]*gameHub{},
 		httpServer: &http.Server{
 			Handler: cors.Default().Handler(mux),
 		},
+		connectTimeout: defaultConnectTimeout,
 	}
+	server.AddGame(game)
 
-	mux.HandleFunc("/games/"+game.ID, server.handleGame)
-	mux.HandleFunc("/games/"+game.ID+"/events", server.handleWebsocket)
+	mux.HandleFunc("/games/", server.handleGames)
+	mux.Handle("/viewer/", http.FileServer(http.FS(viewerFiles)))
 
 	return server
 }
 
+func newGameHub(game Game) *gameHub {
+	return &gameHub{
+		game:      game,
+		updated:   make(chan struct{}),
+		firstTurn: -1,
+		lastTurn:  -1,
+		playback:  defaultPlaybackState(),
+		connected: make(chan bool),
+		delivered: make(chan bool),
+	}
+}
+
+// Register a game so that it can be watched at /games/:id. Registering a game
+// that already exists replaces its metadata but keeps its events.
+func (server *BoardServer) AddGame(game Game) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if hub, ok := server.games[game.ID]; ok {
+		hub.mutex.Lock()
+		hub.game = game
+		hub.mutex.Unlock()
+		return
+	}
+	server.games[game.ID] = newGameHub(game)
+}
+
+func (server *BoardServer) getGame(gameID string) *gameHub {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.games[gameID]
+}
+
+// Route /games/:id, /games/:id/events and /games/:id/control to the handler for the game.
+func (server *BoardServer) handleGames(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/games/")
+	gameID, suffix, _ := strings.Cut(path, "/")
+
+	hub := server.getGame(gameID)
+	if hub == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch suffix {
+	case "":
+		server.handleGame(w, r, hub)
+	case "events":
+		server.handleWebsocket(w, r, hub)
+	case "control":
+		server.handleControl(w, r, hub)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
 // Handle the /games/:id request made by the board to fetch the game metadata.
-func (server *BoardServer) handleGame(w http.ResponseWriter, r *http.Request) {
+func (server *BoardServer) handleGame(w http.ResponseWriter, r *http.Request, hub *gameHub) {
+	hub.mutex.Lock()
+	game := hub.game
+	hub.mutex.Unlock()
+
 	w.Header().Add("Content-Type", "application/json")
 	err := json.NewEncoder(w).Encode(struct {
 		Game Game
-	}{server.game})
+	}{game})
 	if err != nil {
 		log.ERROR.Printf("Unable to serialize game: %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -58,7 +161,9 @@ func (server *BoardServer) handleGame(w http.ResponseWriter, r *http.Request) {
 }
 
 // Handle the /games/:id/events websocket request made by the board to receive game events.
-func (server *BoardServer) handleWebsocket(w http.ResponseWriter, r *http.Request) {
+// Clients are sent every event from the start of the game, followed by new events as they're sent.
+// Playback events are sent whenever the playback state changes, and clients can send PlaybackControl messages.
+func (server *BoardServer) handleWebsocket(w http.ResponseWriter, r *http.Request, hub *gameHub) {
 	ws, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.ERROR.Printf("Unable to upgrade connection: %v", err)
@@ -72,21 +177,38 @@ func (server *BoardServer) handleWebsocket(w http.ResponseWriter, r *http.Reques
 		}
 	}()
 
-	for event := range server.events {
-		jsonStr, err := json.Marshal(event)
-		if err != nil {
-			log.ERROR.Printf("Unable to serialize event for websocket: %v", err)
+	hub.connectedOnce.Do(func() { close(hub.connected) })
+	// Shutdown waits for this, so it must be signalled even if the client goes away
+	defer hub.deliveredOnce.Do(func() { close(hub.delivered) })
+
+	go server.readControls(ws, hub)
+
+	sent := 0
+	sentPlaybackVersion := 0
+	for {
+		events, closed, updated, playback, playbackVersion := hub.eventsFrom(sent)
+		if playbackVersion != sentPlaybackVersion {
+			if err := writeEvent(ws, GameEvent{EventType: EVENT_TYPE_PLAYBACK, Data: playback}); err != nil {
+				return
+			}
+			sentPlaybackVersion = playbackVersion
+		}
+		for _, event := range events {
+			if err := writeEvent(ws, event); err != nil {
+				return
+			}
 		}
+		sent += len(events)
 
-		err = ws.WriteMessage(websocket.TextMessage, jsonStr)
-		if err != nil {
-			log.ERROR.Printf("Unable to write to websocket: %v", err)
+		if closed && len(events) == 0 {
 			break
 		}
+		if len(events) == 0 {
+			<-updated
+		}
 	}
 
 	log.DEBUG.Printf("Finished writing all game events, signalling game server to stop")
-	close(server.done)
 
 	log.DEBUG.Printf("Sending websocket close message")
 	err = ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
@@ -95,6 +217,80 @@ func (server *BoardServer) handleWebsocket(w http.ResponseWriter, r *http.Reques
 	}
 }
 
+func writeEvent(ws *websocket.Conn, event GameEvent) error {
+	jsonStr, err := json.Marshal(event)
+	if err != nil {
+		log.ERROR.Printf("Unable to serialize event for websocket: %v", err)
+	}
+
+	err = ws.WriteMessage(websocket.TextMessage, jsonStr)
+	if err != nil {
+		log.ERROR.Printf("Unable to write to websocket: %v", err)
+	}
+	return err
+}
+
+// Apply the playback controls sent by a client, until the connection is closed.
+func (server *BoardServer) readControls(ws *websocket.Conn, hub *gameHub) {
+	for {
+		var control PlaybackControl
+		if err := ws.ReadJSON(&control); err != nil {
+			if _, ok := err.(*json.SyntaxError); ok {
+				log.WARN.Printf("Ignoring invalid playback control: %v", err)
+				continue
+			}
+			return
+		}
+		if _, err := hub.applyControl(control); err != nil {
+			log.WARN.Printf("Ignoring playback control: %v", err)
+		}
+	}
+}
+
+// Returns the events after the first n, whether the game is finished, a
+// channel that's closed when there's something new, and the playback state.
+func (hub *gameHub) eventsFrom(n int) ([]GameEvent, bool, chan struct{}, PlaybackState, int) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	return hub.history[n:len(hub.history):len(hub.history)], hub.closed, hub.updated, hub.playback, hub.playbackVersion
+}
+
+// Wake up everything waiting for the game to change. Must be called with the mutex held.
+func (hub *gameHub) notify() {
+	close(hub.updated)
+	hub.updated = make(chan struct{})
+}
+
+func (hub *gameHub) addEvent(event GameEvent) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	if hub.closed {
+		log.WARN.Printf("Ignoring event for finished game %s", hub.game.ID)
+		return
+	}
+	hub.history = append(hub.history, event)
+	if frame, ok := event.Data.(GameFrame); ok {
+		if hub.firstTurn < 0 {
+			hub.firstTurn = frame.Turn
+		}
+		hub.lastTurn = frame.Turn
+	}
+	hub.notify()
+}
+
+func (hub *gameHub) finish() {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	if hub.closed {
+		return
+	}
+	hub.closed = true
+	hub.notify()
+}
+
 func (server *BoardServer) Listen() (string, error) {
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -112,11 +308,37 @@ func (server *BoardServer) Listen() (string, error) {
 	return url, nil
 }
 
+// Returns the URL of the embedded viewer for a game, given the URL returned by Listen.
+func ViewerURL(serverURL string, gameID string) string {
+	return serverURL + "/viewer/?game=" + url.QueryEscape(gameID)
+}
+
+// Finish every game, wait for each game to be fully delivered to a client
+// (or for the client to disconnect), and stop the server. Games that no
+// client has connected to yet are given until the connect timeout for one
+// to connect, so that a browser opened for a short game still sees it.
 func (server *BoardServer) Shutdown() {
-	close(server.events)
+	server.mutex.Lock()
+	hubs := make([]*gameHub, 0, len(server.games))
+	for _, hub := range server.games {
+		hubs = append(hubs, hub)
+	}
+	server.mutex.Unlock()
+
+	for _, hub := range hubs {
+		hub.finish()
+	}
 
 	log.DEBUG.Printf("Waiting for websocket clients to finish")
-	<-server.done
+	connectDeadline := time.Now().Add(server.connectTimeout)
+	for _, hub := range hubs {
+		select {
+		case <-hub.connected:
+			<-hub.delivered
+		case <-time.After(time.Until(connectDeadline)):
+			log.DEBUG.Printf("No client connected to a game before the timeout, not waiting for it")
+		}
+	}
 	log.DEBUG.Printf("Server is done, exiting")
 
 	err := server.httpServer.Shutdown(context.Background())
@@ -125,6 +347,26 @@ func (server *BoardServer) Shutdown() {
 	}
 }
 
+// Send an event to the game the server was created with.
 func (server *BoardServer) SendEvent(event GameEvent) {
-	server.events <- event
+	server.SendGameEvent(server.gameID, event)
+}
+
+// Send an event to a registered game. Clients that connect later will receive it too.
+func (server *BoardServer) SendGameEvent(gameID string, event GameEvent) {
+	hub := server.getGame(gameID)
+	if hub == nil {
+		log.ERROR.Printf("Unable to send event for unknown game %s", gameID)
+		return
+	}
+	hub.addEvent(event)
+}
+
+// Signal that no more events will be sent for a game, so that its clients are disconnected once they've caught up.
+func (server *BoardServer) FinishGame(gameID string) {
+	hub := server.getGame(gameID)
+	if hub == nil {
+		return
+	}
+	hub.finish()
 }
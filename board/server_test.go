@@ -0,0 +1,170 @@
+package board
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func readEvents(t *testing.T, url string) []GameEvent {
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	var events []GameEvent
+	for {
+		_, message, err := ws.ReadMessage()
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			return events
+		}
+		require.NoError(t, err)
+
+		var event GameEvent
+		require.NoError(t, json.Unmarshal(message, &event))
+		events = append(events, event)
+	}
+}
+
+func TestBoardServerMultipleGames(t *testing.T) {
+	server := NewBoardServer(Game{ID: "one"})
+	server.AddGame(Game{ID: "two", Width: 7})
+
+	httpServer := httptest.NewServer(server.httpServer.Handler)
+	defer httpServer.Close()
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+
+	response, err := http.Get(httpServer.URL + "/games/two")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	var body struct{ Game Game }
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+	require.Equal(t, "two", body.Game.ID)
+	require.Equal(t, 7, body.Game.Width)
+
+	response, err = http.Get(httpServer.URL + "/games/three")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	server.SendEvent(GameEvent{EventType: EVENT_TYPE_FRAME, Data: GameFrame{Turn: 0}})
+	server.SendGameEvent("two", GameEvent{EventType: EVENT_TYPE_FRAME, Data: GameFrame{Turn: 5}})
+	server.SendEvent(GameEvent{EventType: EVENT_TYPE_GAME_END, Data: Game{ID: "one"}})
+	server.FinishGame("one")
+	server.FinishGame("two")
+
+	// each client should receive the whole game, even after another client has
+	require.Len(t, readEvents(t, wsURL+"/games/one/events"), 2)
+	require.Len(t, readEvents(t, wsURL+"/games/one/events"), 2)
+
+	events := readEvents(t, wsURL+"/games/two/events")
+	require.Len(t, events, 1)
+	require.Equal(t, EVENT_TYPE_FRAME, events[0].EventType)
+
+	// every game has been delivered, so this shouldn't block
+	server.Shutdown()
+}
+
+// Shut down the server, failing the test if it doesn't return.
+func requireShutdown(t *testing.T, server *BoardServer) {
+	done := make(chan struct{})
+	go func() {
+		server.Shutdown()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown didn't return")
+	}
+}
+
+func TestBoardServerShutdown(t *testing.T) {
+	// games that nobody connects to are only waited for until the connect timeout
+	server := NewBoardServer(Game{ID: "one"})
+	server.connectTimeout = 50 * time.Millisecond
+	server.AddGame(Game{ID: "two"})
+	server.SendEvent(GameEvent{EventType: EVENT_TYPE_FRAME, Data: GameFrame{Turn: 0}})
+	requireShutdown(t, server)
+
+	// clients that disconnect part way through a game don't block shutdown
+	server = NewBoardServer(Game{ID: "one"})
+	httpServer := httptest.NewServer(server.httpServer.Handler)
+	defer httpServer.Close()
+	server.SendEvent(GameEvent{EventType: EVENT_TYPE_FRAME, Data: GameFrame{Turn: 0}})
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/games/one/events", nil)
+	require.NoError(t, err)
+	_, _, err = ws.ReadMessage()
+	require.NoError(t, err)
+	require.NoError(t, ws.Close())
+
+	for turn := 1; turn < 50; turn++ {
+		server.SendEvent(GameEvent{EventType: EVENT_TYPE_FRAME, Data: GameFrame{Turn: turn}})
+		time.Sleep(time.Millisecond)
+	}
+	requireShutdown(t, server)
+}
+
+func TestBoardServerShutdownLateClient(t *testing.T) {
+	// a client that connects after the game has finished still receives every event
+	server := NewBoardServer(Game{ID: "one"})
+	httpServer := httptest.NewServer(server.httpServer.Handler)
+	defer httpServer.Close()
+	for turn := 0; turn < 3; turn++ {
+		server.SendEvent(GameEvent{EventType: EVENT_TYPE_FRAME, Data: GameFrame{Turn: turn}})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		server.Shutdown()
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("Shutdown returned before a client connected")
+	default:
+	}
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/games/one/events", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	frames := 0
+	for {
+		var event GameEvent
+		if err := ws.ReadJSON(&event); err != nil {
+			break
+		}
+		if event.EventType == EVENT_TYPE_FRAME {
+			frames++
+		}
+	}
+	require.Equal(t, 3, frames)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown didn't return")
+	}
+}
+
+func TestBoardServerViewer(t *testing.T) {
+	server := NewBoardServer(Game{ID: "GAME_ID"})
+
+	httpServer := httptest.NewServer(server.httpServer.Handler)
+	defer httpServer.Close()
+
+	viewerURL := ViewerURL(httpServer.URL, "GAME_ID")
+	require.Equal(t, httpServer.URL+"/viewer/?game=GAME_ID", viewerURL)
+
+	for _, url := range []string{viewerURL, httpServer.URL + "/viewer/viewer.js", httpServer.URL + "/viewer/viewer.css"} {
+		response, err := http.Get(url)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, response.StatusCode, url)
+	}
+}
@@ -0,0 +1,271 @@
+// A minimal board viewer for games served by the CLI's board server.
+// It renders each frame as it arrives, so it works without an internet connection.
+(function () {
+  "use strict";
+
+  const cellSize = 40;
+  const frameDelay = 150; // milliseconds between frames at normal speed
+  const gameID = new URLSearchParams(window.location.search).get("game");
+  const canvas = document.getElementById("board");
+  const context = canvas.getContext("2d");
+
+  let game = null;
+  let frame = null;
+  let frames = [];
+  let position = 0;
+  let playback = { Paused: false, Turn: -1, Speed: 1 };
+  let socket = null;
+
+  function setStatus(text) {
+    document.getElementById("status").textContent = text;
+  }
+
+  function snakeColor(snake) {
+    return snake.Color || "#888888";
+  }
+
+  // Board coordinates start at the bottom left, canvas coordinates at the top left.
+  function cellRect(point) {
+    return [point.X * cellSize, (game.Height - 1 - point.Y) * cellSize];
+  }
+
+  function fillCell(point, color, inset) {
+    const [x, y] = cellRect(point);
+    context.fillStyle = color;
+    context.fillRect(x + inset, y + inset, cellSize - 2 * inset, cellSize - 2 * inset);
+  }
+
+  function drawBoard() {
+    canvas.width = game.Width * cellSize;
+    canvas.height = game.Height * cellSize;
+
+    context.fillStyle = "#111827";
+    context.fillRect(0, 0, canvas.width, canvas.height);
+    for (let x = 0; x < game.Width; x++) {
+      for (let y = 0; y < game.Height; y++) {
+        fillCell({ X: x, Y: y }, "#1f2937", 1);
+      }
+    }
+    if (!frame) {
+      return;
+    }
+
+    context.globalAlpha = 0.5;
+    for (const hazard of frame.Hazards || []) {
+      fillCell(hazard, "#6b21a8", 1);
+    }
+    context.globalAlpha = 1;
+
+    for (const food of frame.Food || []) {
+      const [x, y] = cellRect(food);
+      context.fillStyle = "#ef4444";
+      context.beginPath();
+      context.arc(x + cellSize / 2, y + cellSize / 2, cellSize / 4, 0, 2 * Math.PI);
+      context.fill();
+    }
+
+    for (const snake of frame.Snakes || []) {
+      // Only show eliminated snakes on the turn they were eliminated
+      if (snake.Death && snake.Death.Turn < frame.Turn) {
+        continue;
+      }
+      context.globalAlpha = snake.Death ? 0.3 : 1;
+      snake.Body.forEach((point, i) => {
+        fillCell(point, snakeColor(snake), i === 0 ? 2 : 5);
+      });
+      if (snake.Body.length > 0) {
+        fillCell(snake.Body[0], "#ffffff", cellSize / 2 - 3);
+      }
+    }
+    context.globalAlpha = 1;
+  }
+
+  function drawSidebar() {
+    document.getElementById("title").textContent = game.RulesetName + " / " + game.Map;
+    if (!frame) {
+      return;
+    }
+    document.getElementById("turn").textContent = "Turn " + frame.Turn;
+
+    const list = document.getElementById("snakes");
+    list.replaceChildren();
+    for (const snake of frame.Snakes || []) {
+      const item = document.createElement("li");
+      item.style.borderColor = snakeColor(snake);
+      if (snake.Death) {
+        item.className = "dead";
+      }
+
+      const name = document.createElement("div");
+      name.className = "name";
+      name.textContent = snake.Name;
+      item.appendChild(name);
+
+      const details = document.createElement("div");
+      details.className = "details";
+      if (snake.Death) {
+        details.textContent = "Eliminated on turn " + snake.Death.Turn + ": " + snake.Death.Cause;
+      } else {
+        details.textContent = "Length " + snake.Body.length + ", latency " + snake.Latency + "ms";
+        if (snake.Error) {
+          details.textContent += ", " + snake.Error;
+        }
+      }
+      item.appendChild(details);
+
+      const health = document.createElement("div");
+      health.className = "health";
+      const bar = document.createElement("div");
+      bar.style.width = (snake.Death ? 0 : snake.Health) + "%";
+      bar.style.background = snakeColor(snake);
+      health.appendChild(bar);
+      item.appendChild(health);
+
+      list.appendChild(item);
+    }
+  }
+
+  function render() {
+    drawBoard();
+    drawSidebar();
+  }
+
+  function showPosition(index) {
+    if (index < 0 || index >= frames.length) {
+      return;
+    }
+    position = index;
+    frame = frames[position];
+    render();
+  }
+
+  function frameIndex(turn) {
+    return frames.findIndex((f) => f.Turn === turn);
+  }
+
+  function updateControls() {
+    document.getElementById("play").innerHTML = playback.Paused ? "&#9654;" : "&#10074;&#10074;";
+    document.getElementById("speed").value = String(playback.Speed);
+    const jump = document.getElementById("jump");
+    jump.max = String(Math.max(frames.length - 1, 0));
+    jump.value = String(position);
+  }
+
+  // Apply the playback state shared by every viewer of the game.
+  function applyPlayback(state) {
+    playback = state;
+    if (playback.Turn >= 0) {
+      showPosition(frameIndex(playback.Turn));
+    }
+    updateControls();
+  }
+
+  // Once the board server has gone away, controls are applied to the frames already received.
+  function applyControlLocally(control) {
+    const currentTurn = frame ? frame.Turn : 0;
+    const firstTurn = frames.length > 0 ? frames[0].Turn : 0;
+    const lastTurn = frames.length > 0 ? frames[frames.length - 1].Turn : 0;
+    const state = Object.assign({}, playback);
+    switch (control.Action) {
+      case "pause":
+        state.Paused = true;
+        state.Turn = currentTurn;
+        break;
+      case "resume":
+        state.Paused = false;
+        state.Turn = -1;
+        break;
+      case "step":
+        state.Paused = true;
+        state.Turn = Math.min(currentTurn + 1, lastTurn);
+        break;
+      case "back":
+        state.Paused = true;
+        state.Turn = Math.max(currentTurn - 1, firstTurn);
+        break;
+      case "jump":
+        state.Paused = true;
+        state.Turn = control.Turn;
+        break;
+      case "speed":
+        state.Speed = control.Speed;
+        break;
+    }
+    applyPlayback(state);
+  }
+
+  function sendControl(control) {
+    if (control.Turn === undefined && frame) {
+      control.Turn = frame.Turn;
+    }
+    if (socket && socket.readyState === WebSocket.OPEN) {
+      socket.send(JSON.stringify(control));
+    } else {
+      applyControlLocally(control);
+    }
+  }
+
+  function tick() {
+    if (!playback.Paused && position < frames.length - 1) {
+      showPosition(position + 1);
+      updateControls();
+    }
+    setTimeout(tick, frameDelay / playback.Speed);
+  }
+
+  function setupControls() {
+    for (const button of document.querySelectorAll("[data-action]")) {
+      button.onclick = () => sendControl({ Action: button.dataset.action });
+    }
+    document.getElementById("play").onclick = () => sendControl({ Action: playback.Paused ? "resume" : "pause" });
+    document.getElementById("jump").oninput = (e) => {
+      const target = frames[Number(e.target.value)];
+      if (target) {
+        sendControl({ Action: "jump", Turn: target.Turn });
+      }
+    };
+    document.getElementById("speed").onchange = (e) => sendControl({ Action: "speed", Speed: Number(e.target.value) });
+  }
+
+  function connect() {
+    const protocol = window.location.protocol === "https:" ? "wss:" : "ws:";
+    socket = new WebSocket(protocol + "//" + window.location.host + "/games/" + encodeURIComponent(gameID) + "/events");
+    socket.onmessage = (message) => {
+      const event = JSON.parse(message.data);
+      if (event.Type === "frame") {
+        // A paused viewer that's showing the latest turn shows turns played by stepping forward
+        const atLatest = position === frames.length - 1;
+        frames.push(event.Data);
+        if (frames.length === 1 || (playback.Paused && playback.Turn < 0 && atLatest)) {
+          showPosition(frames.length - 1);
+        }
+        updateControls();
+      } else if (event.Type === "playback") {
+        applyPlayback(event.Data);
+      } else if (event.Type === "game_end") {
+        setStatus("Game over");
+      }
+    };
+    socket.onerror = () => setStatus("Lost connection to the board server");
+  }
+
+  if (!gameID) {
+    setStatus("No game specified");
+    return;
+  }
+  fetch("/games/" + encodeURIComponent(gameID))
+    .then((response) => {
+      if (!response.ok) {
+        throw new Error("game " + gameID + " not found");
+      }
+      return response.json();
+    })
+    .then((body) => {
+      game = body.Game;
+      render();
+      setupControls();
+      connect();
+      tick();
+    })
+    .catch((err) => setStatus("Unable to load game: " + err.message));
+})();
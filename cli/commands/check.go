@@ -0,0 +1,343 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/spf13/cobra"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+// Longest shout accepted by the Battlesnake engine.
+const maxShoutLength = 256
+
+var snakeColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// The rulesets checked, and the map each of them is played on.
+var checkRulesets = []struct {
+	name    string
+	mapName string
+}{
+	{rules.GameTypeStandard, "standard"},
+	{rules.GameTypeSolo, "standard"},
+	{rules.GameTypeRoyale, "royale"},
+	{rules.GameTypeConstrictor, "standard"},
+	{rules.GameTypeWrapped, "standard"},
+	{rules.GameTypeWrappedConstrictor, "standard"},
+}
+
+var checkBoardSizes = []int{rules.BoardSizeSmall, rules.BoardSizeMedium, rules.BoardSizeLarge}
+
+type CheckState struct {
+	// Options
+	URL     string
+	Timeout int
+	Seed    int64
+
+	// Internal state
+	httpClient TimedHttpClient
+}
+
+// The outcome of a single request made to the snake.
+type checkResult struct {
+	Name    string
+	Latency time.Duration
+	Errors  []string
+}
+
+func (result checkResult) Passed() bool {
+	return len(result.Errors) == 0
+}
+
+func NewCheckCommand() *cobra.Command {
+	checkState := &CheckState{}
+
+	var checkCmd = &cobra.Command{
+		Use:   "check <url>",
+		Short: "Check that a Battlesnake implements the Battlesnake API correctly.",
+		Long: "Check that a Battlesnake implements the Battlesnake API correctly, by sending it metadata, start, move and end requests for every ruleset.\n" +
+			"Each response is checked for the expected status code, content type and body, and for responding within the timeout.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkState.URL = args[0]
+			results, err := checkState.Run()
+			if err != nil {
+				log.ERROR.Fatalf("Error checking snake: %v", err)
+			}
+			if !printCheckResults(results) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	checkCmd.Flags().IntVarP(&checkState.Timeout, "timeout", "t", 500, "Request Timeout")
+	checkCmd.Flags().Int64VarP(&checkState.Seed, "seed", "r", 1, "Random Seed used to generate the boards")
+
+	checkCmd.Flags().SortFlags = false
+
+	return checkCmd
+}
+
+// Send every request to the snake, and return the result of each one.
+func (checkState *CheckState) Run() ([]checkResult, error) {
+	if _, err := url.ParseRequestURI(checkState.URL); err != nil {
+		return nil, fmt.Errorf("URL %v is not valid: %w", checkState.URL, err)
+	}
+	if checkState.Timeout <= 0 {
+		checkState.Timeout = 500
+	}
+	if checkState.httpClient == nil {
+		// Slow responses are reported rather than cut off, as long as they eventually finish
+		checkState.httpClient = timedHTTPClient{
+			&http.Client{
+				Timeout: 4 * time.Duration(checkState.Timeout) * time.Millisecond,
+			},
+		}
+	}
+
+	results := []checkResult{checkState.checkMetadata()}
+	for _, ruleset := range checkRulesets {
+		fixtures, err := checkState.buildFixtures(ruleset.name, ruleset.mapName)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to build boards for %s: %w", ruleset.name, err)
+		}
+
+		results = append(results, checkState.checkRequest(fmt.Sprintf("POST /start (%s)", ruleset.name), "start", fixtures[0].request, nil))
+		for _, fixture := range fixtures {
+			results = append(results, checkState.checkRequest(fmt.Sprintf("POST /move (%s, %s)", ruleset.name, fixture.name), "move", fixture.request, validateMoveResponse))
+		}
+		results = append(results, checkState.checkRequest(fmt.Sprintf("POST /end (%s)", ruleset.name), "end", fixtures[len(fixtures)-1].request, nil))
+	}
+	return results, nil
+}
+
+func (checkState *CheckState) checkMetadata() checkResult {
+	result := checkResult{Name: "GET /"}
+
+	res, latency, err := checkState.httpClient.Get(checkState.URL)
+	result.Latency = latency
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("request failed: %v", err))
+		return result
+	}
+	body, err := checkResponse(&result, res)
+	if err != nil {
+		return result
+	}
+	checkState.checkLatency(&result)
+
+	var metadata client.SnakeMetadataResponse
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid JSON: %v", err))
+		return result
+	}
+	if metadata.APIVersion != "1" {
+		result.Errors = append(result.Errors, fmt.Sprintf("apiversion is %#v, expected \"1\"", metadata.APIVersion))
+	}
+	if metadata.Color != "" && !snakeColorRegex.MatchString(metadata.Color) {
+		result.Errors = append(result.Errors, fmt.Sprintf("color %#v isn't a hex color like \"#ff0000\"", metadata.Color))
+	}
+	return result
+}
+
+// Post a snake request to one of the snake's endpoints. The body of the
+// response is only checked when a validator is given.
+func (checkState *CheckState) checkRequest(name string, endpoint string, request client.SnakeRequest, validate func(*checkResult, []byte)) checkResult {
+	result := checkResult{Name: name}
+
+	u, _ := url.ParseRequestURI(checkState.URL)
+	u.Path = path.Join(u.Path, endpoint)
+	res, latency, err := checkState.httpClient.Post(u.String(), "application/json", bytes.NewBuffer(serialiseSnakeRequest(request)))
+	result.Latency = latency
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("request failed: %v", err))
+		return result
+	}
+	checkState.checkLatency(&result)
+
+	if validate == nil {
+		if res.Body != nil {
+			res.Body.Close()
+		}
+		if res.StatusCode != http.StatusOK {
+			result.Errors = append(result.Errors, fmt.Sprintf("status code %d, expected %d", res.StatusCode, http.StatusOK))
+		}
+		return result
+	}
+
+	body, err := checkResponse(&result, res)
+	if err != nil {
+		return result
+	}
+	validate(&result, body)
+	return result
+}
+
+func (checkState *CheckState) checkLatency(result *checkResult) {
+	timeout := time.Duration(checkState.Timeout) * time.Millisecond
+	if result.Latency > timeout {
+		result.Errors = append(result.Errors, fmt.Sprintf("took %dms, longer than the %dms timeout", result.Latency.Milliseconds(), checkState.Timeout))
+	}
+}
+
+// Check the status code and content type of a response, and read its body.
+func checkResponse(result *checkResult, res *http.Response) ([]byte, error) {
+	if res.Body == nil {
+		result.Errors = append(result.Errors, "empty response body")
+		return nil, fmt.Errorf("empty response body")
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("unable to read response body: %v", err))
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		result.Errors = append(result.Errors, fmt.Sprintf("status code %d, expected %d", res.StatusCode, http.StatusOK))
+	}
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		result.Errors = append(result.Errors, fmt.Sprintf("content type %#v, expected \"application/json\"", res.Header.Get("Content-Type")))
+	}
+	return body, nil
+}
+
+func validateMoveResponse(result *checkResult, body []byte) {
+	var move client.MoveResponse
+	if err := json.Unmarshal(body, &move); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid JSON: %v", err))
+		return
+	}
+	switch move.Move {
+	case rules.MoveUp, rules.MoveDown, rules.MoveLeft, rules.MoveRight:
+	default:
+		result.Errors = append(result.Errors, fmt.Sprintf("move %#v isn't one of \"up\", \"down\", \"left\" or \"right\"", move.Move))
+	}
+	if len(move.Shout) > maxShoutLength {
+		result.Errors = append(result.Errors, fmt.Sprintf("shout is %d characters, longer than the limit of %d", len(move.Shout), maxShoutLength))
+	}
+}
+
+type checkFixture struct {
+	name    string
+	request client.SnakeRequest
+}
+
+// Build the move requests sent for a ruleset: the start of a game on each
+// board size, and a game that's been played for a while.
+func (checkState *CheckState) buildFixtures(rulesetName string, mapName string) ([]checkFixture, error) {
+	gameMap, err := maps.GetMap(mapName)
+	if err != nil {
+		return nil, err
+	}
+
+	snakeIDs := []string{"check-you", "check-opponent"}
+	if rulesetName == rules.GameTypeSolo {
+		snakeIDs = snakeIDs[:1]
+	}
+	snakeStates := map[string]SnakeState{}
+	for _, id := range snakeIDs {
+		snakeStates[id] = SnakeState{ID: id, Name: id, LastMove: rules.MoveUp}
+	}
+
+	ruleset := rules.NewRulesetBuilder().
+		WithSeed(checkState.Seed).
+		WithSolo(len(snakeIDs) < 2).
+		NamedRuleset(rulesetName)
+	gameState := &GameState{
+		Timeout:     checkState.Timeout,
+		gameID:      "check-" + rulesetName,
+		ruleset:     ruleset,
+		gameMap:     gameMap,
+		snakeStates: snakeStates,
+	}
+
+	var fixtures []checkFixture
+	for _, size := range checkBoardSizes {
+		boardState, err := maps.SetupBoardWithMap(gameMap, ruleset.Settings(), size, size, snakeIDs)
+		if err != nil {
+			return nil, err
+		}
+		_, boardState, err = ruleset.Execute(boardState, nil)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, checkFixture{
+			name:    fmt.Sprintf("%dx%d, turn %d", size, size, boardState.Turn),
+			request: gameState.getRequestBodyForSnake(boardState, snakeStates[snakeIDs[0]]),
+		})
+
+		if size == rules.BoardSizeMedium {
+			boardState, err = playCheckFixture(gameMap, ruleset, boardState, 30)
+			if err != nil {
+				return nil, err
+			}
+			fixtures = append(fixtures, checkFixture{
+				name:    fmt.Sprintf("%dx%d, turn %d", size, size, boardState.Turn),
+				request: gameState.getRequestBodyForSnake(boardState, snakeStates[snakeIDs[0]]),
+			})
+		}
+	}
+	return fixtures, nil
+}
+
+// Play a game for some turns, with snakes that move in a loop so that they
+// stay alive, to get a board with longer snakes, food and hazards.
+func playCheckFixture(gameMap maps.GameMap, ruleset rules.Ruleset, boardState *rules.BoardState, turns int) (*rules.BoardState, error) {
+	loop := []string{rules.MoveUp, rules.MoveRight, rules.MoveDown, rules.MoveLeft}
+	for i := 0; i < turns; i++ {
+		next, err := maps.PreUpdateBoard(gameMap, boardState, ruleset.Settings())
+		if err != nil {
+			return nil, err
+		}
+		var moves []rules.SnakeMove
+		for _, snake := range next.Snakes {
+			moves = append(moves, rules.SnakeMove{ID: snake.ID, Move: loop[i%len(loop)]})
+		}
+		gameOver, next, err := ruleset.Execute(next, moves)
+		if err != nil {
+			return nil, err
+		}
+		next, err = maps.PostUpdateBoard(gameMap, next, ruleset.Settings())
+		if err != nil {
+			return nil, err
+		}
+		next.Turn += 1
+		if gameOver {
+			break
+		}
+		boardState = next
+	}
+	return boardState, nil
+}
+
+// Print a line for each check, followed by a summary. Returns whether every check passed.
+func printCheckResults(results []checkResult) bool {
+	passed := 0
+	for _, result := range results {
+		status := "FAIL"
+		if result.Passed() {
+			status = "PASS"
+			passed++
+		}
+		fmt.Printf("%s  %-44s %5dms\n", status, result.Name, result.Latency.Milliseconds())
+		for _, e := range result.Errors {
+			fmt.Printf("      - %s\n", e)
+		}
+	}
+	fmt.Printf("\n%d/%d checks passed\n", passed, len(results))
+	return passed == len(results)
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/stretchr/testify/require"
+)
+
+func newCheckTestServer(t *testing.T, metadata string, move string, contentType string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", contentType)
+			_, _ = w.Write([]byte(metadata))
+		case "/start", "/end":
+			w.WriteHeader(http.StatusOK)
+		case "/move":
+			var request client.SnakeRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			require.NotEmpty(t, request.You.ID)
+			w.Header().Set("Content-Type", contentType)
+			_, _ = w.Write([]byte(move))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestCheckPasses(t *testing.T) {
+	server := newCheckTestServer(t, `{"apiversion": "1", "color": "#123456"}`, `{"move": "up", "shout": "hi"}`, "application/json; charset=utf-8")
+	defer server.Close()
+
+	checkState := &CheckState{URL: server.URL, Timeout: 500, Seed: 1}
+	results, err := checkState.Run()
+	require.NoError(t, err)
+
+	// metadata, then start, a move for each board, and end for each ruleset
+	require.Len(t, results, 1+len(checkRulesets)*(2+len(checkBoardSizes)+1))
+	for _, result := range results {
+		require.True(t, result.Passed(), "%s: %v", result.Name, result.Errors)
+	}
+	require.True(t, printCheckResults(results))
+}
+
+func TestCheckFails(t *testing.T) {
+	server := newCheckTestServer(t, `{"apiversion": "2", "color": "red"}`, `{"move": "north"}`, "text/plain")
+	defer server.Close()
+
+	checkState := &CheckState{URL: server.URL, Timeout: 500, Seed: 1}
+	results, err := checkState.Run()
+	require.NoError(t, err)
+	require.False(t, printCheckResults(results))
+
+	metadata := strings.Join(results[0].Errors, "\n")
+	require.Contains(t, metadata, `content type "text/plain"`)
+	require.Contains(t, metadata, `apiversion is "2"`)
+	require.Contains(t, metadata, `color "red"`)
+
+	require.True(t, results[1].Passed(), "start requests only need a successful status code")
+	move := strings.Join(results[2].Errors, "\n")
+	require.Contains(t, move, `move "north"`)
+
+	_, err = (&CheckState{URL: "not a url"}).Run()
+	require.Error(t, err)
+}
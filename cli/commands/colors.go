@@ -11,4 +11,17 @@ const (
 	TERM_FG_LIGHTGRAY = "\033[38;2;200;200;200m"
 	TERM_FG_FOOD      = "\033[38;2;255;92;117m"
 	TERM_FG_RGB       = "\033[38;2;%d;%d;%dm"
+
+	TERM_FG_ELIMINATED = "\033[38;2;220;38;38m"
+	TERM_BOLD          = "\033[1m"
+)
+
+// ANSI escape codes used to draw the full-screen terminal UI
+const (
+	TERM_ALT_SCREEN   = "\033[?1049h"
+	TERM_MAIN_SCREEN  = "\033[?1049l"
+	TERM_HIDE_CURSOR  = "\033[?25l"
+	TERM_SHOW_CURSOR  = "\033[?25h"
+	TERM_CLEAR_SCREEN = "\033[H\033[2J"
+	TERM_CLEAR_LINE   = "\033[K"
 )
@@ -0,0 +1,362 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+type CompareState struct {
+	// Options
+	URLA       string
+	URLB       string
+	NameA      string
+	NameB      string
+	Pool       []string
+	MaxPairs   int
+	MinPairs   int
+	Confidence float64
+	Width      int
+	Height     int
+	GameType   string
+	MapName    string
+	Timeout    int
+	Seed       int64
+
+	// Internal state
+	playGame func(gameState *GameState) (*gameResult, error)
+}
+
+// How one version did in the games played so far.
+type versionRecord struct {
+	Name   string
+	URL    string
+	Wins   int
+	Draws  int
+	Losses int
+}
+
+func (record *versionRecord) Games() int {
+	return record.Wins + record.Draws + record.Losses
+}
+
+// Add the result of a game, and return the version's score for it: 1 for a
+// win, 0.5 for a draw and 0 for a loss.
+func (record *versionRecord) Add(result *gameResult, snakeID string) float64 {
+	switch {
+	case result.IsDraw:
+		record.Draws++
+		return 0.5
+	case result.Winner.ID == snakeID:
+		record.Wins++
+		return 1
+	default:
+		record.Losses++
+		return 0
+	}
+}
+
+// The outcome of a comparison, from the difference between A's and B's
+// scores in each pair of games.
+type comparison struct {
+	A, B        versionRecord
+	Differences []float64
+	Mean        float64
+	Low, High   float64 // confidence interval for the mean difference
+	Confidence  float64 // confidence level of the interval, adjusted for the number of times it's checked
+	Significant bool
+}
+
+func NewCompareCommand() *cobra.Command {
+	compareState := &CompareState{}
+
+	var compareCmd = &cobra.Command{
+		Use:   "compare",
+		Short: "Compare two versions of a Battlesnake by playing games between them.",
+		Long: "Play pairs of games to find out whether version A of a Battlesnake is better than version B.\n" +
+			"Without a pool, A and B play each other, and each pair of games uses the same seed with their start positions swapped. " +
+			"With a pool, A and B each play against the pool snakes, and each pair of games uses the same seed and start positions.\n" +
+			"From --min-pairs pairs on, the difference between A and B is checked after every pair, and games stop once it's significant or after --pairs pairs. " +
+			"Each check uses a stricter confidence level, splitting the chance of a false positive allowed by --confidence evenly between the checks (a Bonferroni correction), " +
+			"so stopping early doesn't make a false positive more likely than --confidence allows.",
+		Run: func(cmd *cobra.Command, args []string) {
+			// The progress of the comparison is easier to follow without the log of each game
+			if !verbose {
+				log.SetStdoutThreshold(log.LevelWarn)
+			}
+			result, err := compareState.Run()
+			if err != nil {
+				log.ERROR.Fatalf("Error comparing snakes: %v", err)
+			}
+			printComparison(result, compareState.Confidence)
+		},
+	}
+
+	compareCmd.Flags().StringVar(&compareState.URLA, "a", "", "URL of version A of the Battlesnake")
+	compareCmd.Flags().StringVar(&compareState.URLB, "b", "", "URL of version B of the Battlesnake")
+	compareCmd.Flags().StringVar(&compareState.NameA, "name-a", "A", "Name of version A")
+	compareCmd.Flags().StringVar(&compareState.NameB, "name-b", "B", "Name of version B")
+	compareCmd.Flags().StringArrayVar(&compareState.Pool, "pool", nil, "URL of a snake for A and B to play against, instead of each other")
+	compareCmd.Flags().IntVar(&compareState.MaxPairs, "pairs", 50, "Maximum number of pairs of games to play")
+	compareCmd.Flags().IntVar(&compareState.MinPairs, "min-pairs", 10, "Number of pairs of games to play before stopping early")
+	compareCmd.Flags().Float64Var(&compareState.Confidence, "confidence", 0.95, "Confidence level used to decide when the difference is significant")
+	compareCmd.Flags().IntVarP(&compareState.Width, "width", "W", 11, "Width of Board")
+	compareCmd.Flags().IntVarP(&compareState.Height, "height", "H", 11, "Height of Board")
+	compareCmd.Flags().StringVarP(&compareState.GameType, "gametype", "g", "standard", "Type of Game Rules")
+	compareCmd.Flags().StringVarP(&compareState.MapName, "map", "m", "standard", "Game map to use to populate the board. Join map IDs with + to combine them, e.g. royale+healing_pools")
+	compareCmd.Flags().IntVarP(&compareState.Timeout, "timeout", "t", 500, "Request Timeout")
+	compareCmd.Flags().Int64VarP(&compareState.Seed, "seed", "r", time.Now().UTC().UnixNano(), "Random Seed used to choose the seed of each pair of games")
+	_ = compareCmd.MarkFlagRequired("a")
+	_ = compareCmd.MarkFlagRequired("b")
+
+	compareCmd.Flags().SortFlags = false
+
+	return compareCmd
+}
+
+// Play pairs of games until the result is significant, the maximum number of
+// pairs have been played, or the comparison is interrupted.
+func (compareState *CompareState) Run() (*comparison, error) {
+	if compareState.NameA == compareState.NameB {
+		return nil, fmt.Errorf("Versions A and B need different names")
+	}
+	if compareState.MaxPairs <= 0 {
+		return nil, fmt.Errorf("The number of pairs must be greater than 0")
+	}
+	if compareState.Confidence <= 0 || compareState.Confidence >= 1 {
+		return nil, fmt.Errorf("The confidence level must be between 0 and 1")
+	}
+	if compareState.playGame == nil {
+		compareState.playGame = func(gameState *GameState) (*gameResult, error) {
+			if err := gameState.Initialize(); err != nil {
+				return nil, err
+			}
+			return gameState.playGame()
+		}
+	}
+
+	interrupted, stopInterrupts := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopInterrupts()
+
+	result := &comparison{
+		A: versionRecord{Name: compareState.NameA, URL: compareState.URLA},
+		B: versionRecord{Name: compareState.NameB, URL: compareState.URLB},
+	}
+	result.Confidence = sequentialConfidence(compareState.Confidence, compareState.MinPairs, compareState.MaxPairs)
+	seeds := rand.New(rand.NewSource(compareState.Seed))
+	for pair := 0; pair < compareState.MaxPairs; pair++ {
+		seed := seeds.Int63()
+
+		var games []*gameResult
+		var err error
+		if len(compareState.Pool) == 0 {
+			games, err = compareState.playHeadToHead(result, seed)
+		} else {
+			games, err = compareState.playAgainstPool(result, seed, pair)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if interrupted.Err() != nil || games == nil {
+			log.WARN.Printf("Comparison interrupted after %d pairs of games", len(result.Differences))
+			break
+		}
+
+		// In head to head games both versions play in each game, and against
+		// the pool each version plays in one of them
+		var scoreA, scoreB float64
+		for i, game := range games {
+			if len(compareState.Pool) == 0 || i == 0 {
+				scoreA += result.A.Add(game, result.A.Name)
+			}
+			if len(compareState.Pool) == 0 || i == 1 {
+				scoreB += result.B.Add(game, result.B.Name)
+			}
+		}
+		difference := scoreA - scoreB
+		if len(compareState.Pool) == 0 {
+			difference /= 2
+		}
+
+		result.Differences = append(result.Differences, difference)
+		result.Mean, result.Low, result.High = meanConfidenceInterval(result.Differences, result.Confidence)
+		// Scores are between 0 and 1, so the difference can't be outside -1 and 1
+		result.Low, result.High = math.Max(-1, result.Low), math.Min(1, result.High)
+		result.Significant = len(result.Differences) >= compareState.MinPairs && (result.Low > 0 || result.High < 0)
+		fmt.Printf("Pair %d (seed %d): %s %g - %g %s\n", pair+1, seed, result.A.Name, scoreA, scoreB, result.B.Name)
+
+		if result.Significant {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Play A against B twice with the same seed, swapping their start positions
+// for the second game. Returns nil when a game is interrupted.
+func (compareState *CompareState) playHeadToHead(result *comparison, seed int64) ([]*gameResult, error) {
+	return compareState.playGames(
+		compareState.buildGame(seed, []*versionRecord{&result.A, &result.B}, nil),
+		compareState.buildGame(seed, []*versionRecord{&result.B, &result.A}, nil),
+	)
+}
+
+// Play A and then B against the pool with the same seed and start positions.
+// The start position used rotates with each pair. Returns nil when a game is
+// interrupted.
+func (compareState *CompareState) playAgainstPool(result *comparison, seed int64, pair int) ([]*gameResult, error) {
+	return compareState.playGames(
+		compareState.buildGame(seed, []*versionRecord{&result.A}, &pair),
+		compareState.buildGame(seed, []*versionRecord{&result.B}, &pair),
+	)
+}
+
+func (compareState *CompareState) playGames(gameStates ...*GameState) ([]*gameResult, error) {
+	var results []*gameResult
+	for _, gameState := range gameStates {
+		result, err := compareState.playGame(gameState)
+		if err != nil || result == nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Set up a game between versions, in the order given, and the pool snakes.
+// When a slot is given, the first version is moved to that start position
+// among the pool snakes.
+func (compareState *CompareState) buildGame(seed int64, versions []*versionRecord, slot *int) *GameState {
+	var names, urls []string
+	for _, version := range versions {
+		names = append(names, version.Name)
+		urls = append(urls, version.URL)
+	}
+	for i, poolURL := range compareState.Pool {
+		names = append(names, fmt.Sprintf("Pool %d", i+1))
+		urls = append(urls, poolURL)
+	}
+	if slot != nil {
+		position := *slot % len(names)
+		names[0], names[position] = names[position], names[0]
+		urls[0], urls[position] = urls[position], urls[0]
+	}
+
+	return &GameState{
+		Width:               compareState.Width,
+		Height:              compareState.Height,
+		Names:               names,
+		URLs:                urls,
+		Timeout:             compareState.Timeout,
+		GameType:            compareState.GameType,
+		MapName:             compareState.MapName,
+		Seed:                seed,
+		FoodSpawnChance:     15,
+		MinimumFood:         1,
+		HazardDamagePerTurn: 14,
+		ShrinkEveryNTurns:   25,
+		// Snakes are identified by name, so that the winner can be found
+		idGenerator: func(i int) string { return names[i] },
+	}
+}
+
+// The confidence level to check the difference at after each pair, so that
+// the chance of a false positive over every check is at most 1 - confidence.
+// The result is checked after every pair from minPairs (and at least 2, since
+// there's no interval for a single pair) to maxPairs, and the allowed error is
+// split evenly between those checks.
+func sequentialConfidence(confidence float64, minPairs, maxPairs int) float64 {
+	firstCheck := minPairs
+	if firstCheck < 2 {
+		firstCheck = 2
+	}
+	checks := maxPairs - firstCheck + 1
+	if checks < 1 {
+		checks = 1
+	}
+	return 1 - (1-confidence)/float64(checks)
+}
+
+// The mean of the values, and its confidence interval using the normal
+// approximation.
+func meanConfidenceInterval(values []float64, confidence float64) (float64, float64, float64) {
+	n := float64(len(values))
+	if n == 0 {
+		return 0, 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / n
+	if n < 2 {
+		return mean, math.Inf(-1), math.Inf(1)
+	}
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	standardError := math.Sqrt(squares/(n-1)) / math.Sqrt(n)
+	margin := normalQuantile(confidence) * standardError
+	return mean, mean - margin, mean + margin
+}
+
+// The Wilson score interval for a proportion.
+func wilsonInterval(successes int, trials int, confidence float64) (float64, float64) {
+	if trials == 0 {
+		return 0, 1
+	}
+	z := normalQuantile(confidence)
+	n := float64(trials)
+	p := float64(successes) / n
+	center := (p + z*z/(2*n)) / (1 + z*z/n)
+	margin := z / (1 + z*z/n) * math.Sqrt(p*(1-p)/n+z*z/(4*n*n))
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// The z value for a two-sided confidence level, such as 1.96 for 0.95.
+func normalQuantile(confidence float64) float64 {
+	return math.Sqrt2 * math.Erfinv(confidence)
+}
+
+func printComparison(result *comparison, confidence float64) {
+	percent := fmt.Sprintf("%g%%", confidence*100)
+	fmt.Println()
+	for _, record := range []versionRecord{result.A, result.B} {
+		low, high := wilsonInterval(record.Wins, record.Games(), confidence)
+		winRate := 0.0
+		if record.Games() > 0 {
+			winRate = float64(record.Wins) / float64(record.Games())
+		}
+		fmt.Printf("%s (%s): %d wins, %d draws, %d losses, win rate %.1f%% (%s CI %.1f%% to %.1f%%)\n",
+			record.Name, record.URL, record.Wins, record.Draws, record.Losses, winRate*100, percent, low*100, high*100)
+	}
+
+	pairs := len(result.Differences)
+	if pairs < 2 {
+		fmt.Printf("\nNot enough games were played to compare %s and %s\n", result.A.Name, result.B.Name)
+		return
+	}
+	fmt.Printf("\nDifference in score per game: %+.2f (%g%% CI %+.2f to %+.2f, adjusted for checking after every pair) over %d pairs of games\n",
+		result.Mean, result.Confidence*100, result.Low, result.High, pairs)
+
+	var conclusion string
+	switch {
+	case !result.Significant:
+		conclusion = fmt.Sprintf("No significant difference between %s and %s", result.A.Name, result.B.Name)
+	case result.Mean > 0:
+		conclusion = fmt.Sprintf("%s is better than %s", result.A.Name, result.B.Name)
+	default:
+		conclusion = fmt.Sprintf("%s is better than %s", result.B.Name, result.A.Name)
+	}
+	fmt.Println(conclusion)
+}
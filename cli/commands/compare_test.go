@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareHeadToHead(t *testing.T) {
+	var games []*GameState
+	compareState := &CompareState{
+		URLA: "http://a", URLB: "http://b", NameA: "A", NameB: "B",
+		MaxPairs: 50, MinPairs: 5, Confidence: 0.95, Seed: 1,
+		playGame: func(gameState *GameState) (*gameResult, error) {
+			games = append(games, gameState)
+			// A wins every game
+			return &gameResult{Winner: SnakeState{ID: "A"}}, nil
+		},
+	}
+
+	result, err := compareState.Run()
+	require.NoError(t, err)
+	require.True(t, result.Significant)
+	require.Len(t, result.Differences, 5)
+	require.InDelta(t, 1-0.05/46, result.Confidence, 1e-12)
+	require.Equal(t, 1.0, result.Mean)
+	require.Equal(t, versionRecord{Name: "A", URL: "http://a", Wins: 10}, result.A)
+	require.Equal(t, versionRecord{Name: "B", URL: "http://b", Losses: 10}, result.B)
+
+	// each pair uses the same seed with the snakes swapped
+	require.Len(t, games, 10)
+	require.Equal(t, games[0].Seed, games[1].Seed)
+	require.NotEqual(t, games[0].Seed, games[2].Seed)
+	require.Equal(t, []string{"A", "B"}, games[0].Names)
+	require.Equal(t, []string{"http://a", "http://b"}, games[0].URLs)
+	require.Equal(t, []string{"B", "A"}, games[1].Names)
+	require.Equal(t, "B", games[1].idGenerator(0))
+}
+
+func TestCompareAgainstPool(t *testing.T) {
+	var games []*GameState
+	turn := 0
+	compareState := &CompareState{
+		URLA: "http://a", URLB: "http://b", NameA: "A", NameB: "B", Pool: []string{"http://pool"},
+		MaxPairs: 6, MinPairs: 5, Confidence: 0.95, Seed: 1,
+		playGame: func(gameState *GameState) (*gameResult, error) {
+			games = append(games, gameState)
+			// A and B each draw half of their games, and lose the rest
+			turn++
+			if turn%4 < 2 {
+				return &gameResult{Winner: SnakeState{ID: "Pool 1"}}, nil
+			}
+			return &gameResult{IsDraw: true}, nil
+		},
+	}
+
+	result, err := compareState.Run()
+	require.NoError(t, err)
+	require.False(t, result.Significant)
+	require.Len(t, result.Differences, 6)
+	require.Equal(t, 0.0, result.Mean)
+	require.Equal(t, versionRecord{Name: "A", URL: "http://a", Draws: 3, Losses: 3}, result.A)
+
+	// the version's start position rotates each pair, and is the same for both versions
+	require.Equal(t, []string{"A", "Pool 1"}, games[0].Names)
+	require.Equal(t, []string{"B", "Pool 1"}, games[1].Names)
+	require.Equal(t, []string{"Pool 1", "A"}, games[2].Names)
+	require.Equal(t, []string{"Pool 1", "B"}, games[3].Names)
+	require.Equal(t, games[2].Seed, games[3].Seed)
+}
+
+func TestCompareInvalidOptions(t *testing.T) {
+	_, err := (&CompareState{NameA: "A", NameB: "A", MaxPairs: 1, Confidence: 0.95}).Run()
+	require.Error(t, err)
+	_, err = (&CompareState{NameA: "A", NameB: "B", MaxPairs: 0, Confidence: 0.95}).Run()
+	require.Error(t, err)
+	_, err = (&CompareState{NameA: "A", NameB: "B", MaxPairs: 1, Confidence: 1}).Run()
+	require.Error(t, err)
+}
+
+func TestCompareMirroredStartPositions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"apiversion": "1"}`))
+	}))
+	defer server.Close()
+
+	compareState := &CompareState{URLA: server.URL, URLB: server.URL, Width: 11, Height: 11, GameType: "standard", MapName: "standard", Timeout: 500}
+	a, b := &versionRecord{Name: "A", URL: server.URL}, &versionRecord{Name: "B", URL: server.URL}
+
+	heads := func(gameState *GameState) map[string]interface{} {
+		require.NoError(t, gameState.Initialize())
+		var err error
+		gameState.snakeStates, err = gameState.buildSnakesFromOptions()
+		require.NoError(t, err)
+		_, boardState, err := gameState.initializeBoardFromArgs()
+		require.NoError(t, err)
+		heads := map[string]interface{}{}
+		for _, snake := range boardState.Snakes {
+			heads[snake.ID] = snake.Body[0]
+		}
+		return heads
+	}
+
+	first := heads(compareState.buildGame(42, []*versionRecord{a, b}, nil))
+	second := heads(compareState.buildGame(42, []*versionRecord{b, a}, nil))
+	require.Equal(t, first["A"], second["B"])
+	require.Equal(t, first["B"], second["A"])
+	require.NotEqual(t, first["A"], first["B"])
+}
+
+func TestSequentialConfidence(t *testing.T) {
+	// the result is checked after pairs 10 to 50, so each check allows 1/41 of the error
+	require.InDelta(t, 1-0.05/41, sequentialConfidence(0.95, 10, 50), 1e-12)
+	// there's nothing to check before the second pair
+	require.InDelta(t, 1-0.05/9, sequentialConfidence(0.95, 0, 10), 1e-12)
+	// checking once doesn't need a correction
+	require.InDelta(t, 0.95, sequentialConfidence(0.95, 10, 10), 1e-12)
+	require.InDelta(t, 0.95, sequentialConfidence(0.95, 20, 10), 1e-12)
+}
+
+func TestConfidenceIntervals(t *testing.T) {
+	require.InDelta(t, 1.96, normalQuantile(0.95), 0.001)
+
+	mean, low, high := meanConfidenceInterval([]float64{1, 0, 1, 0}, 0.95)
+	require.Equal(t, 0.5, mean)
+	require.InDelta(t, 0.5-1.96*math.Sqrt(1.0/3)/2, low, 0.0001)
+	require.InDelta(t, 0.5+1.96*math.Sqrt(1.0/3)/2, high, 0.0001)
+
+	_, low, high = meanConfidenceInterval([]float64{1}, 0.95)
+	require.True(t, math.IsInf(low, -1))
+	require.True(t, math.IsInf(high, 1))
+
+	low, high = wilsonInterval(5, 10, 0.95)
+	require.InDelta(t, 0.2366, low, 0.0001)
+	require.InDelta(t, 0.7634, high, 0.0001)
+
+	low, high = wilsonInterval(0, 0, 0.95)
+	require.Equal(t, 0.0, low)
+	require.Equal(t, 1.0, high)
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/BattlesnakeOfficial/rules/scenario"
+	"github.com/spf13/cobra"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+type DiffSnakeState struct {
+	// Options
+	URL     string
+	Timeout int
+
+	// Internal state
+	agent scenario.Agent
+}
+
+// A recorded move request replayed against the new version of a snake.
+type snakeDiff struct {
+	GameID       string
+	Turn         int
+	SnakeName    string
+	RecordedMove string
+	Move         string
+	Error        error
+}
+
+func (diff snakeDiff) Matches() bool {
+	return diff.Error == nil && diff.Move == diff.RecordedMove
+}
+
+func NewDiffSnakeCommand() *cobra.Command {
+	diffSnakeState := &DiffSnakeState{}
+
+	var diffSnakeCmd = &cobra.Command{
+		Use:   "diff-snake <recording>",
+		Short: "Replay recorded move requests against another version of a Battlesnake.",
+		Long: "Send each move request in a file written by the record command to a Battlesnake, along with the start and end requests for each game, and report every turn where it chooses a different move than the one recorded.\n" +
+			"The command exits with a non-zero status if any move is different.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			exchanges, err := readRecording(args[0])
+			if err != nil {
+				log.ERROR.Fatalf("Error reading recording: %v", err)
+			}
+			diffs := diffSnakeState.Run(exchanges)
+			if len(diffs) == 0 {
+				log.ERROR.Fatalf("No move requests with a recorded move were found in %s", args[0])
+			}
+			if !printSnakeDiffs(diffs) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	diffSnakeCmd.Flags().StringVarP(&diffSnakeState.URL, "url", "u", "", "URL of the Battlesnake to compare with the recording")
+	diffSnakeCmd.Flags().IntVarP(&diffSnakeState.Timeout, "timeout", "t", 500, "Request Timeout")
+	_ = diffSnakeCmd.MarkFlagRequired("url")
+
+	diffSnakeCmd.Flags().SortFlags = false
+
+	return diffSnakeCmd
+}
+
+// Agents that also need to be told when each game starts and ends, such as
+// snakes that keep state for each game.
+type gameLifecycleAgent interface {
+	Start(request client.SnakeRequest) error
+	End(request client.SnakeRequest) error
+}
+
+// Replay every recorded move request that got a valid move in response.
+// Recorded start and end requests are replayed in order around the moves, so
+// snakes that keep state for each game see the same sequence of requests.
+func (diffSnakeState *DiffSnakeState) Run(exchanges []recordedExchange) []snakeDiff {
+	if diffSnakeState.agent == nil {
+		if diffSnakeState.Timeout <= 0 {
+			diffSnakeState.Timeout = 500
+		}
+		diffSnakeState.agent = scenario.HTTPAgent{
+			URL: diffSnakeState.URL,
+			Client: &http.Client{
+				Timeout: time.Duration(diffSnakeState.Timeout) * time.Millisecond,
+			},
+		}
+	}
+	lifecycleAgent, _ := diffSnakeState.agent.(gameLifecycleAgent)
+
+	var diffs []snakeDiff
+	for _, exchange := range exchanges {
+		if exchange.Method != http.MethodPost {
+			continue
+		}
+		var request client.SnakeRequest
+		if json.Unmarshal(exchange.Request, &request) != nil {
+			continue
+		}
+
+		switch {
+		case strings.HasSuffix(exchange.Path, "/start") && lifecycleAgent != nil:
+			if err := lifecycleAgent.Start(request); err != nil {
+				log.WARN.Printf("Start request for game %s failed: %v", request.Game.ID, err)
+			}
+		case strings.HasSuffix(exchange.Path, "/end") && lifecycleAgent != nil:
+			if err := lifecycleAgent.End(request); err != nil {
+				log.WARN.Printf("End request for game %s failed: %v", request.Game.ID, err)
+			}
+		case strings.HasSuffix(exchange.Path, "/move") && exchange.StatusCode == http.StatusOK:
+			var recorded client.MoveResponse
+			if json.Unmarshal(exchange.Response, &recorded) != nil || recorded.Move == "" {
+				continue
+			}
+
+			diff := snakeDiff{
+				GameID:       request.Game.ID,
+				Turn:         request.Turn,
+				SnakeName:    request.You.Name,
+				RecordedMove: recorded.Move,
+			}
+			response, err := diffSnakeState.agent.Move(request)
+			diff.Move = response.Move
+			diff.Error = err
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs
+}
+
+// Print a line for each move that's different, followed by a summary. Returns whether every move matched.
+func printSnakeDiffs(diffs []snakeDiff) bool {
+	matched := 0
+	for _, diff := range diffs {
+		if diff.Matches() {
+			matched++
+			continue
+		}
+		if diff.Error != nil {
+			fmt.Printf("Game %s, turn %d (%s): recorded %s, request failed: %v\n", diff.GameID, diff.Turn, diff.SnakeName, diff.RecordedMove, diff.Error)
+		} else {
+			fmt.Printf("Game %s, turn %d (%s): recorded %s, now %s\n", diff.GameID, diff.Turn, diff.SnakeName, diff.RecordedMove, diff.Move)
+		}
+	}
+	fmt.Printf("\n%d/%d moves matched the recording\n", matched, len(diffs))
+	return matched == len(diffs)
+}
@@ -0,0 +1,230 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Network conditions simulated for the move requests sent to a snake. Each
+// chance is a probability between 0 and 1, rolled for every request.
+type faultConfig struct {
+	DelayChance    float64
+	MaxDelay       time.Duration
+	DropChance     float64
+	ErrorChance    float64
+	TruncateChance float64
+}
+
+// Faults for the snake with a name, or for every snake when the name is empty.
+type faultSpec struct {
+	snakeName string
+	config    faultConfig
+}
+
+// Parse a fault given on the command-line, in the form
+// "[snake name:]delay=0.2,max-delay=300,drop=0.05,error=0.05,truncate=0.05".
+func parseFaultSpec(value string) (faultSpec, error) {
+	spec := faultSpec{config: faultConfig{MaxDelay: 250 * time.Millisecond}}
+
+	options := value
+	if i := strings.LastIndex(value, ":"); i >= 0 {
+		spec.snakeName = value[:i]
+		options = value[i+1:]
+	}
+
+	for _, option := range strings.Split(options, ",") {
+		key, rawValue, found := strings.Cut(strings.TrimSpace(option), "=")
+		if !found {
+			return spec, fmt.Errorf("fault option %#v should be in the form key=value", option)
+		}
+		if key == "max-delay" {
+			ms, err := strconv.Atoi(rawValue)
+			if err != nil || ms <= 0 {
+				return spec, fmt.Errorf("max-delay %#v should be a positive number of milliseconds", rawValue)
+			}
+			spec.config.MaxDelay = time.Duration(ms) * time.Millisecond
+			continue
+		}
+
+		chance, err := strconv.ParseFloat(rawValue, 64)
+		if err != nil || chance < 0 || chance > 1 {
+			return spec, fmt.Errorf("%s %#v should be a probability between 0 and 1", key, rawValue)
+		}
+		switch key {
+		case "delay":
+			spec.config.DelayChance = chance
+		case "drop":
+			spec.config.DropChance = chance
+		case "error":
+			spec.config.ErrorChance = chance
+		case "truncate":
+			spec.config.TruncateChance = chance
+		default:
+			return spec, fmt.Errorf("unknown fault %#v, expected one of delay, max-delay, drop, error or truncate", key)
+		}
+	}
+	return spec, nil
+}
+
+// Find the faults for a snake. Faults given for the snake's name take
+// precedence over the ones given for every snake.
+func faultsForSnake(specs []faultSpec, snakeName string) (faultConfig, bool) {
+	var config faultConfig
+	found := false
+	for _, spec := range specs {
+		if spec.snakeName == snakeName {
+			return spec.config, true
+		}
+		if spec.snakeName == "" {
+			config = spec.config
+			found = true
+		}
+	}
+	return config, found
+}
+
+// The error returned for requests that don't get a response before the
+// timeout, matching the errors returned by http.Client.
+type faultTimeoutError struct {
+	reason string
+}
+
+func (e faultTimeoutError) Error() string {
+	return fmt.Sprintf("%s (Client.Timeout exceeded while awaiting headers)", e.reason)
+}
+
+func (e faultTimeoutError) Timeout() bool {
+	return true
+}
+
+// A TimedHttpClient that simulates delays, dropped requests, server errors
+// and truncated bodies. The random source is seeded, so the same faults
+// happen each time a game is played with the same seed.
+type faultInjectingClient struct {
+	client  TimedHttpClient
+	config  faultConfig
+	timeout time.Duration
+
+	mutex sync.Mutex
+	rand  *rand.Rand
+	sleep func(time.Duration)
+}
+
+// Create a client for a snake, with its own random source so that the faults
+// for each snake don't depend on the order requests are made in.
+func newFaultInjectingClient(client TimedHttpClient, config faultConfig, timeout time.Duration, seed int64, snakeName string) *faultInjectingClient {
+	hash := fnv.New64a()
+	hash.Write([]byte(snakeName))
+	return &faultInjectingClient{
+		client:  client,
+		config:  config,
+		timeout: timeout,
+		rand:    rand.New(rand.NewSource(seed ^ int64(hash.Sum64()))),
+		sleep:   time.Sleep,
+	}
+}
+
+// The faults chosen for a single request.
+type faultRoll struct {
+	delay    time.Duration
+	drop     bool
+	error    bool
+	truncate bool
+}
+
+func (faultClient *faultInjectingClient) roll() faultRoll {
+	faultClient.mutex.Lock()
+	defer faultClient.mutex.Unlock()
+
+	// Every value is drawn for every request, so that changing one chance
+	// doesn't change which requests the other faults happen to
+	var roll faultRoll
+	delayRoll, delayAmount := faultClient.rand.Float64(), faultClient.rand.Int63n(int64(faultClient.config.MaxDelay)+1)
+	if delayRoll < faultClient.config.DelayChance {
+		roll.delay = time.Duration(delayAmount)
+	}
+	roll.drop = faultClient.rand.Float64() < faultClient.config.DropChance
+	roll.error = faultClient.rand.Float64() < faultClient.config.ErrorChance
+	roll.truncate = faultClient.rand.Float64() < faultClient.config.TruncateChance
+	return roll
+}
+
+func (faultClient *faultInjectingClient) Get(url string) (*http.Response, time.Duration, error) {
+	return faultClient.do(http.MethodGet, url, func() (*http.Response, time.Duration, error) {
+		return faultClient.client.Get(url)
+	})
+}
+
+func (faultClient *faultInjectingClient) Post(url string, contentType string, body io.Reader) (*http.Response, time.Duration, error) {
+	return faultClient.do(http.MethodPost, url, func() (*http.Response, time.Duration, error) {
+		return faultClient.client.Post(url, contentType, body)
+	})
+}
+
+func (faultClient *faultInjectingClient) do(method string, requestURL string, send func() (*http.Response, time.Duration, error)) (*http.Response, time.Duration, error) {
+	roll := faultClient.roll()
+
+	// A dropped request never gets a response, so it always times out
+	if roll.drop {
+		faultClient.sleep(faultClient.timeout)
+		return nil, faultClient.timeout, faultClient.timeoutError(method, requestURL, "request dropped")
+	}
+
+	if roll.delay > 0 {
+		if roll.delay >= faultClient.timeout {
+			faultClient.sleep(faultClient.timeout)
+			return nil, faultClient.timeout, faultClient.timeoutError(method, requestURL, fmt.Sprintf("request delayed by %v", roll.delay))
+		}
+		faultClient.sleep(roll.delay)
+	}
+
+	if roll.error {
+		response := &http.Response{
+			Status:     "500 Internal Server Error",
+			StatusCode: http.StatusInternalServerError,
+			Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       ioutil.NopCloser(strings.NewReader("injected server error")),
+		}
+		return response, roll.delay, nil
+	}
+
+	// The delay counts towards the timeout, so a slow response after a delay times out
+	res, latency, err := send()
+	latency += roll.delay
+	if err == nil && latency >= faultClient.timeout {
+		if res.Body != nil {
+			res.Body.Close()
+		}
+		return nil, faultClient.timeout, faultClient.timeoutError(method, requestURL, fmt.Sprintf("request delayed by %v", roll.delay))
+	}
+	if err != nil || !roll.truncate || res.Body == nil {
+		return res, latency, err
+	}
+
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, latency, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body[:len(body)/2]))
+	res.ContentLength = int64(len(body) / 2)
+	return res, latency, nil
+}
+
+func (faultClient *faultInjectingClient) timeoutError(method string, requestURL string, reason string) error {
+	return &url.Error{
+		Op:  method[:1] + strings.ToLower(method[1:]),
+		URL: requestURL,
+		Err: faultTimeoutError{reason: reason},
+	}
+}
@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFaultSpec(t *testing.T) {
+	spec, err := parseFaultSpec("delay=0.5,max-delay=100,drop=0.1,error=0.2,truncate=0.3")
+	require.NoError(t, err)
+	require.Equal(t, faultSpec{config: faultConfig{
+		DelayChance:    0.5,
+		MaxDelay:       100 * time.Millisecond,
+		DropChance:     0.1,
+		ErrorChance:    0.2,
+		TruncateChance: 0.3,
+	}}, spec)
+
+	spec, err = parseFaultSpec("Snake: 1:drop=1")
+	require.NoError(t, err)
+	require.Equal(t, "Snake: 1", spec.snakeName)
+	require.Equal(t, 1.0, spec.config.DropChance)
+	require.Equal(t, 250*time.Millisecond, spec.config.MaxDelay)
+
+	for _, invalid := range []string{"drop", "drop=2", "delay=x", "max-delay=0", "lag=0.1"} {
+		_, err = parseFaultSpec(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestFaultsForSnake(t *testing.T) {
+	specs := []faultSpec{
+		{snakeName: "one", config: faultConfig{DropChance: 1}},
+		{config: faultConfig{ErrorChance: 1}},
+	}
+
+	config, ok := faultsForSnake(specs, "one")
+	require.True(t, ok)
+	require.Equal(t, faultConfig{DropChance: 1}, config)
+
+	config, ok = faultsForSnake(specs, "two")
+	require.True(t, ok)
+	require.Equal(t, faultConfig{ErrorChance: 1}, config)
+
+	_, ok = faultsForSnake(specs[:1], "two")
+	require.False(t, ok)
+}
+
+func newTestFaultClient(config faultConfig) (*faultInjectingClient, *time.Duration) {
+	stub := stubHTTPClient{nil, http.StatusOK, func(_ string) string { return `{"move": "left"}` }, 10 * time.Millisecond}
+	faultClient := newFaultInjectingClient(stub, config, 500*time.Millisecond, 1, "snake")
+	var slept time.Duration
+	faultClient.sleep = func(d time.Duration) { slept += d }
+	return faultClient, &slept
+}
+
+func TestFaultInjectingClient(t *testing.T) {
+	t.Run("no faults", func(t *testing.T) {
+		faultClient, slept := newTestFaultClient(faultConfig{MaxDelay: time.Second})
+		res, latency, err := faultClient.Post("http://example.com/move", "application/json", nil)
+		require.NoError(t, err)
+		body, _ := ioutil.ReadAll(res.Body)
+		require.Equal(t, `{"move": "left"}`, string(body))
+		require.Equal(t, 10*time.Millisecond, latency)
+		require.Zero(t, *slept)
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		faultClient, slept := newTestFaultClient(faultConfig{DropChance: 1, MaxDelay: time.Second})
+		_, latency, err := faultClient.Post("http://example.com/move", "application/json", nil)
+		require.EqualError(t, err, `Post "http://example.com/move": request dropped (Client.Timeout exceeded while awaiting headers)`)
+		var urlErr *url.Error
+		require.ErrorAs(t, err, &urlErr)
+		require.True(t, urlErr.Timeout())
+		require.Equal(t, 500*time.Millisecond, latency)
+		require.Equal(t, 500*time.Millisecond, *slept)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		faultClient, _ := newTestFaultClient(faultConfig{ErrorChance: 1, MaxDelay: time.Second})
+		res, _, err := faultClient.Get("http://example.com")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		faultClient, _ := newTestFaultClient(faultConfig{TruncateChance: 1, MaxDelay: time.Second})
+		res, _, err := faultClient.Post("http://example.com/move", "application/json", nil)
+		require.NoError(t, err)
+		body, _ := ioutil.ReadAll(res.Body)
+		require.Equal(t, `{"move":`, string(body))
+	})
+
+	t.Run("delay", func(t *testing.T) {
+		faultClient, slept := newTestFaultClient(faultConfig{DelayChance: 1, MaxDelay: 400 * time.Millisecond})
+		for i := 0; i < 20; i++ {
+			*slept = 0
+			_, latency, err := faultClient.Post("http://example.com/move", "application/json", nil)
+			require.NoError(t, err)
+			require.LessOrEqual(t, *slept, 400*time.Millisecond)
+			require.Equal(t, *slept+10*time.Millisecond, latency)
+		}
+
+		faultClient, _ = newTestFaultClient(faultConfig{DelayChance: 1, MaxDelay: 10 * time.Second})
+		timeouts := 0
+		for i := 0; i < 20; i++ {
+			if _, _, err := faultClient.Post("http://example.com/move", "application/json", nil); err != nil {
+				timeouts++
+			}
+		}
+		require.Greater(t, timeouts, 0)
+	})
+
+	t.Run("delay and slow response", func(t *testing.T) {
+		stub := stubHTTPClient{nil, http.StatusOK, func(_ string) string { return `{"move": "left"}` }, 200 * time.Millisecond}
+		faultClient := newFaultInjectingClient(stub, faultConfig{DelayChance: 1, MaxDelay: 400 * time.Millisecond}, 500*time.Millisecond, 1, "snake")
+		var slept time.Duration
+		faultClient.sleep = func(d time.Duration) { slept = d }
+
+		// delays shorter than the timeout still time out once the response time is added
+		timeouts, moves := 0, 0
+		for i := 0; i < 20; i++ {
+			_, latency, err := faultClient.Post("http://example.com/move", "application/json", nil)
+			if slept+200*time.Millisecond >= 500*time.Millisecond {
+				require.ErrorContains(t, err, "Client.Timeout exceeded")
+				require.Equal(t, 500*time.Millisecond, latency)
+				timeouts++
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, slept+200*time.Millisecond, latency)
+				moves++
+			}
+		}
+		require.Greater(t, timeouts, 0)
+		require.Greater(t, moves, 0)
+	})
+}
+
+func TestFaultInjectingClientIsSeeded(t *testing.T) {
+	config := faultConfig{DelayChance: 0.3, MaxDelay: time.Second, DropChance: 0.2, ErrorChance: 0.2, TruncateChance: 0.2}
+	rolls := func(seed int64, snakeName string) []faultRoll {
+		faultClient := newFaultInjectingClient(nil, config, time.Second, seed, snakeName)
+		var rolls []faultRoll
+		for i := 0; i < 50; i++ {
+			rolls = append(rolls, faultClient.roll())
+		}
+		return rolls
+	}
+
+	require.Equal(t, rolls(1, "one"), rolls(1, "one"))
+	require.NotEqual(t, rolls(1, "one"), rolls(2, "one"))
+	require.NotEqual(t, rolls(1, "one"), rolls(1, "two"))
+}
+
+func TestGetSnakeUpdateWithFaults(t *testing.T) {
+	gameState := buildDefaultGameState()
+	gameState.Faults = []string{"one:error=1", "truncate=1"}
+	require.NoError(t, gameState.Initialize())
+	gameState.httpClient = stubHTTPClient{nil, http.StatusOK, func(_ string) string { return `{"move": "left"}` }, 10 * time.Millisecond}
+	gameState.snakeStates = map[string]SnakeState{
+		"1": {ID: "1", Name: "one", URL: "http://example.com/one", LastMove: rules.MoveUp},
+		"2": {ID: "2", Name: "two", URL: "http://example.com/two", LastMove: rules.MoveUp},
+		"3": {ID: "3", Name: "human", URL: humanSnakeURL, LastMove: rules.MoveUp},
+	}
+	var err error
+	gameState.moveHTTPClients, err = gameState.buildMoveHTTPClients()
+	require.NoError(t, err)
+	require.Len(t, gameState.moveHTTPClients, 2)
+
+	boardState := rules.NewBoardState(11, 11).WithSnakes([]rules.Snake{
+		{ID: "1", Body: []rules.Point{{X: 1, Y: 1}}},
+		{ID: "2", Body: []rules.Point{{X: 5, Y: 5}}},
+	})
+
+	snakeState := gameState.getSnakeUpdate(boardState, gameState.snakeStates["1"])
+	require.Equal(t, http.StatusInternalServerError, snakeState.StatusCode)
+	require.Equal(t, rules.MoveUp, snakeState.LastMove)
+
+	snakeState = gameState.getSnakeUpdate(boardState, gameState.snakeStates["2"])
+	require.Error(t, snakeState.Error)
+	require.Equal(t, rules.MoveUp, snakeState.LastMove)
+
+	gameState.faultSpecs = []faultSpec{{snakeName: "three"}}
+	_, err = gameState.buildMoveHTTPClients()
+	require.EqualError(t, err, `Faults were given for "three", but there's no snake with that name`)
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+// Used in place of a snake URL to control the snake from the keyboard.
+const humanSnakeURL = "human"
+
+// Color used for snakes controlled from the keyboard, since they don't have any metadata.
+const humanSnakeColor = "#ffffff"
+
+var errHumanTimeout = errors.New("no move was chosen before the deadline")
+
+var humanMoveKeys = map[string]string{
+	"\033[A": rules.MoveUp,
+	"\033[B": rules.MoveDown,
+	keyLeft:  rules.MoveLeft,
+	keyRight: rules.MoveRight,
+	"w":      rules.MoveUp,
+	"s":      rules.MoveDown,
+	"a":      rules.MoveLeft,
+	"d":      rules.MoveRight,
+}
+
+func isHumanSnake(snakeState SnakeState) bool {
+	return snakeState.URL == humanSnakeURL
+}
+
+// Reads the moves of a snake controlled from the keyboard. Key presses are
+// read in the background, and only the ones made while a move is being
+// chosen are used.
+type keyboardInput struct {
+	input      io.Reader
+	keyPresses bool // whether to switch the terminal to read single key presses
+	keys       chan string
+}
+
+func newKeyboardInput(input io.Reader, keyPresses bool) *keyboardInput {
+	keyboard := &keyboardInput{
+		input:      input,
+		keyPresses: keyPresses,
+		keys:       make(chan string, 16),
+	}
+	go keyboard.readKeys()
+	return keyboard
+}
+
+func (keyboard *keyboardInput) readKeys() {
+	defer close(keyboard.keys)
+	reader := bufio.NewReader(keyboard.input)
+	buf := make([]byte, 16)
+	for {
+		n, err := reader.Read(buf)
+		if err != nil {
+			return
+		}
+		// Without single key presses, keys are only read once enter is pressed
+		key := strings.TrimSpace(string(buf[:n]))
+		select {
+		case keyboard.keys <- key:
+		default:
+		}
+	}
+}
+
+// Switch the terminal to read single key presses without echoing them, for
+// the whole game, so that keys pressed between moves aren't shown either.
+// Returns a function that switches it back, which must be called before
+// exiting.
+func (keyboard *keyboardInput) start() func() {
+	if !keyboard.keyPresses {
+		return func() {}
+	}
+	restore, err := enableCbreakMode()
+	if err != nil {
+		log.WARN.Printf("Unable to read single key presses, press enter after each move: %v", err)
+		return func() {}
+	}
+	return restore
+}
+
+// Wait for a move key to be pressed, until the deadline.
+func (keyboard *keyboardInput) readMove(deadline time.Duration) (string, error) {
+	// Ignore keys pressed while waiting for the other snakes
+	for len(keyboard.keys) > 0 {
+		<-keyboard.keys
+	}
+
+	timeout := time.After(deadline)
+	for {
+		select {
+		case key, ok := <-keyboard.keys:
+			if !ok {
+				return "", io.EOF
+			}
+			if move, ok := humanMoveKeys[key]; ok {
+				return move, nil
+			}
+		case <-timeout:
+			return "", errHumanTimeout
+		}
+	}
+}
+
+// Show the board to the player and wait for them to choose a move. Like a
+// snake that times out, the last move is used when they don't choose in time.
+func (gameState *GameState) getHumanSnakeUpdate(boardState *rules.BoardState, snakeState SnakeState) SnakeState {
+	snakeState.StatusCode = 0
+	snakeState.Error = nil
+	snakeState.Latency = 0
+	snakeState.Shout = ""
+
+	gameState.printMap(boardState)
+	fmt.Printf("%s, choose a move with the arrow keys or WASD within %dms (last move: %s)\n", snakeState.Name, gameState.HumanTimeout, snakeState.LastMove)
+
+	startTime := time.Now()
+	move, err := gameState.keyboard.readMove(time.Duration(gameState.HumanTimeout) * time.Millisecond)
+	snakeState.Latency = time.Since(startTime)
+	if err != nil {
+		log.WARN.Printf("%s didn't choose a move: %v", snakeState.Name, err)
+		snakeState.Error = err
+		return snakeState
+	}
+
+	snakeState.StatusCode = http.StatusOK
+	snakeState.LastMove = move
+	return snakeState
+}
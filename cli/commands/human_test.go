@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyboardInputReadMove(t *testing.T) {
+	reader, writer := io.Pipe()
+	keyboard := newKeyboardInput(reader, false)
+
+	// keys pressed before a move is asked for are ignored, so wait before pressing them
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_, _ = writer.Write([]byte("x\n"))
+		_, _ = writer.Write([]byte("\033[D"))
+	}()
+	move, err := keyboard.readMove(time.Second)
+	require.NoError(t, err)
+	require.Equal(t, rules.MoveLeft, move)
+
+	_, err = keyboard.readMove(10 * time.Millisecond)
+	require.ErrorIs(t, err, errHumanTimeout)
+
+	require.NoError(t, writer.Close())
+	_, err = keyboard.readMove(time.Second)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestHumanSnake(t *testing.T) {
+	gameState := buildDefaultGameState()
+	gameState.URLs = []string{humanSnakeURL, "http://example.com"}
+	gameState.HumanTimeout = 10
+	require.NoError(t, gameState.Initialize())
+
+	reader, writer := io.Pipe()
+	gameState.keyboard = newKeyboardInput(reader, false)
+	gameState.httpClient = stubHTTPClient{nil, http.StatusOK, func(url string) string {
+		require.NotContains(t, url, humanSnakeURL)
+		return `{"move": "left"}`
+	}, time.Millisecond}
+
+	snakeStates, err := gameState.buildSnakesFromOptions()
+	require.NoError(t, err)
+	require.Len(t, snakeStates, 2)
+
+	var human SnakeState
+	for _, snakeState := range snakeStates {
+		if isHumanSnake(snakeState) {
+			human = snakeState
+		}
+	}
+	require.Equal(t, humanSnakeColor, human.Color)
+	gameState.snakeStates = snakeStates
+
+	boardState := rules.NewBoardState(11, 11).WithSnakes([]rules.Snake{{ID: human.ID, Body: []rules.Point{{X: 5, Y: 5}}, Health: 100}})
+
+	// the last move is kept when no move is chosen in time
+	human.LastMove = rules.MoveDown
+	update := gameState.getSnakeUpdate(boardState, human)
+	require.Equal(t, rules.MoveDown, update.LastMove)
+	require.ErrorIs(t, update.Error, errHumanTimeout)
+
+	gameState.HumanTimeout = 1000
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_, _ = writer.Write([]byte("w\n"))
+	}()
+	update = gameState.getSnakeUpdate(boardState, human)
+	require.Equal(t, rules.MoveUp, update.LastMove)
+	require.NoError(t, update.Error)
+	require.Equal(t, http.StatusOK, update.StatusCode)
+
+	gameState.URLs = []string{humanSnakeURL, humanSnakeURL}
+	require.Error(t, gameState.Initialize())
+}
+
+func TestHumanSnakeAfterOtherSnakes(t *testing.T) {
+	gameState := buildDefaultGameState()
+	gameState.URLs = []string{humanSnakeURL, "http://example.com"}
+	gameState.HumanTimeout = 10
+	require.NoError(t, gameState.Initialize())
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	gameState.keyboard = newKeyboardInput(reader, false)
+
+	// the board is shown to the player once the other snake has responded
+	var responded int32
+	gameState.httpClient = stubHTTPClient{nil, http.StatusOK, func(url string) string {
+		time.Sleep(20 * time.Millisecond)
+		atomic.StoreInt32(&responded, 1)
+		return `{"move": "left"}`
+	}, time.Millisecond}
+	var err error
+	gameState.snakeStates, err = gameState.buildSnakesFromOptions()
+	require.NoError(t, err)
+	_, boardState, err := gameState.initializeBoardFromArgs()
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, _, err = gameState.createNextBoardState(boardState)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&responded))
+	require.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/spf13/cobra"
 	log "github.com/spf13/jwalterweatherman"
@@ -67,6 +68,13 @@ func (m *mapInfo) display(id string) {
 	fmt.Println("Author:", meta.Author)
 	fmt.Println("Description:", meta.Description)
 	fmt.Println("Version:", meta.Version)
+	if versions := maps.Versions(gameMap.ID()); len(versions) > 1 {
+		fmt.Print("Available Versions:")
+		for _, v := range versions {
+			fmt.Printf(" %s", maps.VersionedMapID(gameMap.ID(), v))
+		}
+		fmt.Print("\n")
+	}
 	fmt.Println("Min Players:", meta.MinPlayers)
 	fmt.Println("Max Players:", meta.MaxPlayers)
 	fmt.Print("Board Sizes (WxH):")
@@ -86,4 +94,21 @@ func (m *mapInfo) display(id string) {
 			fmt.Print("\n")
 		}
 	}
+	if len(meta.Parameters) > 0 {
+		fmt.Println("Parameters (set with play --param key=value):")
+		for _, p := range meta.Parameters {
+			fmt.Printf("  %s (%s, default %s): %s\n", p.Name, p.Type, p.Default, p.Description)
+		}
+	}
+	if len(meta.DefaultSettings) > 0 {
+		fmt.Println("Default Settings:")
+		keys := make([]string, 0, len(meta.DefaultSettings))
+		for key := range meta.DefaultSettings {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("  %s: %s\n", key, meta.DefaultSettings[key])
+		}
+	}
 }
@@ -1,16 +1,25 @@
 package commands
 
 import (
+	"fmt"
+
+	"github.com/BattlesnakeOfficial/rules/maps"
 	"github.com/spf13/cobra"
 	log "github.com/spf13/jwalterweatherman"
 )
 
 func NewMapCommand() *cobra.Command {
+	var mapDir string
 
 	var mapCmd = &cobra.Command{
 		Use:   "map",
 		Short: "Display map information",
 		Long:  "Display map information",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if _, err := loadCustomMaps(mapDir, ""); err != nil {
+				log.ERROR.Fatal(err)
+			}
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			err := cmd.Help()
 			if err != nil {
@@ -19,5 +28,31 @@ func NewMapCommand() *cobra.Command {
 		},
 	}
 
+	mapCmd.PersistentFlags().StringVar(&mapDir, "map-dir", "", "Directory of JSON or YAML custom map files to include")
+
 	return mapCmd
 }
+
+// Load the custom maps in a directory and a single map file into the map
+// registry. Returns the ID of the map in the file, if there is one.
+func loadCustomMaps(mapDir string, mapFile string) (string, error) {
+	if mapDir != "" {
+		ids, err := maps.LoadDir(mapDir)
+		if err != nil {
+			return "", fmt.Errorf("Failed to load maps from %s: %w", mapDir, err)
+		}
+		log.DEBUG.Printf("Loaded maps from %s: %v", mapDir, ids)
+	}
+
+	if mapFile == "" {
+		return "", nil
+	}
+	m, err := maps.LoadFileMap(mapFile)
+	if err != nil {
+		return "", fmt.Errorf("Failed to load map file: %w", err)
+	}
+	if err := maps.RegisterFileMap(m); err != nil {
+		return "", fmt.Errorf("Failed to load map file %s: %w", mapFile, err)
+	}
+	return m.ID(), nil
+}
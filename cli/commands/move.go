@@ -17,13 +17,18 @@ import (
 type MoveState struct {
 	Request client.SnakeRequest `json:"request"`
 	Moves   []string            `json:"moves"`
+	// The state the map keeps between turns, as output with the previous
+	// request. It isn't part of the API request, but some maps need it.
+	GameState  map[string]string    `json:"gameState,omitempty"`
+	PointState []exportedPointState `json:"pointState,omitempty"`
 }
 
 func NewMoveCommand() *cobra.Command {
 	var playCmd = &cobra.Command{
 		Use:   "move",
 		Short: "Apply moves to the API request from stdin",
-		Long:  "Apply moves to the API request from stdin. Print results to stdout",
+		Long: "Apply moves to the API request from stdin. Print results to stdout.\n" +
+			"Each result includes the map's state as gameState and pointState, which should be passed with the next request.",
 		Run: func(cmd *cobra.Command, args []string) {
 			move()
 		},
@@ -46,97 +51,115 @@ func move() {
 			errLog.Print(err)
 			break
 		}
-		req := state.Request
-
-		// Convert API settings to map params
-		params := map[string]string{
-			rules.ParamFoodSpawnChance:     fmt.Sprint(req.Game.Ruleset.Settings.FoodSpawnChance),
-			rules.ParamMinimumFood:         fmt.Sprint(req.Game.Ruleset.Settings.MinimumFood),
-			rules.ParamHazardDamagePerTurn: fmt.Sprint(req.Game.Ruleset.Settings.HazardDamagePerTurn),
-			rules.ParamShrinkEveryNTurns:   fmt.Sprint(req.Game.Ruleset.Settings.RoyaleSettings.ShrinkEveryNTurns),
-		}
 
-		ruleset := rules.NewRulesetBuilder().WithSeed(0).WithParams(params).NamedRuleset(req.Game.Ruleset.Name)
-		mapID := req.Game.Map
+		result, err := applyMoves(state)
+		if err != nil {
+			errLog.Fatal(err)
+		}
 
-		gameMap, err := maps.GetMap(mapID)
+		newRequestJson, err := json.Marshal(result)
 		if err != nil {
-			errLog.Fatalf("Error getting map: %v", err)
+			errLog.Fatalf("Error marshalling: %v", err)
 		}
 
-		settings := ruleset.Settings().WithRand(rules.MaxRand)
+		os.Stdout.Write(newRequestJson)
+	}
+}
 
-		snakeMap := map[string]client.Snake{}
-		youID := req.You.ID
-		youIdx := 0
+// Apply the moves to the board in a request, and return the request for the
+// next turn along with the map's state.
+func applyMoves(state MoveState) (exportedTurn, error) {
+	req := state.Request
 
-		snakeIds := []string{}
+	// Convert API settings to map params
+	params := paramsFromRulesetSettings(req.Game.Ruleset.Settings)
 
-		for _, s := range req.Board.Snakes {
-			snakeIds = append(snakeIds, s.ID)
-		}
+	ruleset := rules.NewRulesetBuilder().WithSeed(0).WithParams(params).NamedRuleset(req.Game.Ruleset.Name)
+	mapID := req.Game.Map
 
-		// Initialize board, boardState
-		boardState, err := maps.SetupBoard(mapID, settings, req.Board.Width, req.Board.Height, snakeIds)
-		if err != nil {
-			errLog.Fatalf("Error producing next board state: %v", err)
-		}
+	gameMap, err := maps.GetMap(mapID)
+	if err != nil {
+		return exportedTurn{}, fmt.Errorf("Error getting map: %v", err)
+	}
 
-		for i, s := range req.Board.Snakes {
-			boardState.Snakes[i].Health = s.Health
-			boardState.Snakes[i].Body = PointFromCoordArray(s.Body)
-		}
+	settings := ruleset.Settings().WithRand(rules.MaxRand)
 
-		boardState.Turn = req.Turn
-		boardState.Food = PointFromCoordArray(req.Board.Food)
-		boardState.Hazards = PointFromCoordArray(req.Board.Hazards)
+	snakeMap := map[string]client.Snake{}
+	youID := req.You.ID
+	youIdx := 0
 
-		moves := []rules.SnakeMove{}
+	snakeIds := []string{}
 
-		for i, move := range state.Moves {
-			moves = append(moves, rules.SnakeMove{
-				ID:   req.Board.Snakes[i].ID,
-				Move: move,
-			})
-		}
+	for _, s := range req.Board.Snakes {
+		snakeIds = append(snakeIds, s.ID)
+	}
 
-		boardState, err = maps.PreUpdateBoard(gameMap, boardState, settings)
-		if err != nil {
-			errLog.Fatalf("Error PreUpdateBoard: %v", err)
-		}
+	// Initialize board, boardState
+	boardState, err := maps.SetupBoard(mapID, settings, req.Board.Width, req.Board.Height, snakeIds)
+	if err != nil {
+		return exportedTurn{}, fmt.Errorf("Error producing next board state: %v", err)
+	}
 
-		_, boardState, err = ruleset.Execute(boardState, moves)
-		if err != nil {
-			errLog.Fatalf("Error executing move: %v", err)
-		}
+	for i, s := range req.Board.Snakes {
+		boardState.Snakes[i].Health = s.Health
+		boardState.Snakes[i].Body = PointFromCoordArray(s.Body)
+	}
 
-		boardState, err = maps.PostUpdateBoard(gameMap, boardState, settings)
-		if err != nil {
-			errLog.Fatalf("Error PreUpdateBoard: %v", err)
-		}
+	boardState.Turn = req.Turn
+	boardState.Food = PointFromCoordArray(req.Board.Food)
+	boardState.Hazards = PointFromCoordArray(req.Board.Hazards)
+	// The map's state comes from the previous turn rather than the new board,
+	// so things the map placed while setting up aren't put back
+	boardState.GameState = make(map[string]string, len(state.GameState))
+	for key, value := range state.GameState {
+		boardState.GameState[key] = value
+	}
+	boardState.PointState = importPointState(state.PointState)
 
-		newBoard := client.Board{
-			Height:  boardState.Height,
-			Width:   boardState.Width,
-			Food:    client.CoordFromPointArray(boardState.Food),
-			Hazards: client.CoordFromPointArray(boardState.Hazards),
-			Snakes:  convertRulesAPISnakes(boardState.Snakes, snakeMap),
-		}
+	moves := []rules.SnakeMove{}
 
-		newRequest := client.SnakeRequest{
-			Game:  req.Game,
-			Turn:  boardState.Turn + 1,
-			Board: newBoard,
-			You:   convertRulesAPISnake(boardState.Snakes[youIdx], snakeMap[youID]),
-		}
+	for i, move := range state.Moves {
+		moves = append(moves, rules.SnakeMove{
+			ID:   req.Board.Snakes[i].ID,
+			Move: move,
+		})
+	}
 
-		newRequestJson, err := json.Marshal(newRequest)
-		if err != nil {
-			errLog.Fatalf("Error marshalling: %v", err)
-		}
+	boardState, err = maps.PreUpdateBoard(gameMap, boardState, settings)
+	if err != nil {
+		return exportedTurn{}, fmt.Errorf("Error PreUpdateBoard: %v", err)
+	}
 
-		os.Stdout.Write(newRequestJson)
+	_, boardState, err = ruleset.Execute(boardState, moves)
+	if err != nil {
+		return exportedTurn{}, fmt.Errorf("Error executing move: %v", err)
 	}
+
+	boardState, err = maps.PostUpdateBoard(gameMap, boardState, settings)
+	if err != nil {
+		return exportedTurn{}, fmt.Errorf("Error PreUpdateBoard: %v", err)
+	}
+
+	newBoard := client.Board{
+		Height:  boardState.Height,
+		Width:   boardState.Width,
+		Food:    client.CoordFromPointArray(boardState.Food),
+		Hazards: client.CoordFromPointArray(boardState.Hazards),
+		Snakes:  convertRulesAPISnakes(boardState.Snakes, snakeMap),
+	}
+
+	newRequest := client.SnakeRequest{
+		Game:  req.Game,
+		Turn:  boardState.Turn + 1,
+		Board: newBoard,
+		You:   convertRulesAPISnake(boardState.Snakes[youIdx], snakeMap[youID]),
+	}
+
+	return exportedTurn{
+		SnakeRequest: newRequest,
+		GameState:    boardState.GameState,
+		PointState:   exportPointState(boardState.PointState),
+	}, nil
 }
 
 func convertRulesAPISnake(snake rules.Snake, snakeState client.Snake) client.Snake {
@@ -170,6 +193,16 @@ func convertRulesAPISnakes(snakes []rules.Snake, snakeStates map[string]client.S
 	return a
 }
 
+// Converts the static RulesetSettings used in the client API back into ruleset parameters.
+func paramsFromRulesetSettings(settings client.RulesetSettings) map[string]string {
+	return map[string]string{
+		rules.ParamFoodSpawnChance:     fmt.Sprint(settings.FoodSpawnChance),
+		rules.ParamMinimumFood:         fmt.Sprint(settings.MinimumFood),
+		rules.ParamHazardDamagePerTurn: fmt.Sprint(settings.HazardDamagePerTurn),
+		rules.ParamShrinkEveryNTurns:   fmt.Sprint(settings.RoyaleSettings.ShrinkEveryNTurns),
+	}
+}
+
 func PointFromCoord(crd client.Coord) rules.Point {
 	return rules.Point{X: crd.X, Y: crd.Y}
 }
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+func snailModeMoveState() MoveState {
+	snake := client.Snake{ID: "one", Health: 100, Body: []client.Coord{{X: 5, Y: 5}, {X: 5, Y: 4}, {X: 5, Y: 3}}}
+	return MoveState{
+		Request: client.SnakeRequest{
+			Game:  client.Game{ID: "GAME_ID", Ruleset: client.Ruleset{Name: rules.GameTypeSolo}, Map: "snail_mode"},
+			Board: client.Board{Width: 11, Height: 11, Food: []client.Coord{}, Hazards: []client.Coord{}, Snakes: []client.Snake{snake}},
+			You:   snake,
+		},
+		Moves: []string{rules.MoveUp},
+	}
+}
+
+func TestApplyMovesMapState(t *testing.T) {
+	first, err := applyMoves(snailModeMoveState())
+	require.NoError(t, err)
+	require.Equal(t, 1, first.Turn)
+	require.Contains(t, first.GameState, "snail_mode.tails")
+	require.Empty(t, first.Board.Hazards)
+
+	// the map's state is passed on to the next move, so the trail is placed behind the snake
+	next := MoveState{Request: first.SnakeRequest, Moves: []string{rules.MoveUp}, GameState: first.GameState}
+	second, err := applyMoves(next)
+	require.NoError(t, err)
+	require.Equal(t, 2, second.Turn)
+	require.Equal(t, []client.Coord{{X: 5, Y: 4}, {X: 5, Y: 4}, {X: 5, Y: 4}}, second.Board.Hazards)
+
+	// without it, the map doesn't know where the tail was
+	next.GameState = nil
+	second, err = applyMoves(next)
+	require.NoError(t, err)
+	require.Empty(t, second.Board.Hazards)
+}
+
+func TestApplyMovesEntities(t *testing.T) {
+	// the map's state from the previous turn has a ghost that has moved away from where it started
+	previous := rules.NewBoardState(11, 11)
+	ghost := maps.Entity{ID: "ghost", Body: []rules.Point{{X: 3, Y: 3}}, Velocity: rules.Point{X: 1, Y: 0}, Path: maps.EntityPathBounce}
+	require.NoError(t, maps.PlaceEntity(maps.NewBoardStateEditor(previous), ghost))
+
+	state := snailModeMoveState()
+	state.Request.Game.Map = "standard"
+	state.Request.Board.Hazards = []client.Coord{{X: 3, Y: 3}}
+	state.GameState = previous.GameState
+	result, err := applyMoves(state)
+	require.NoError(t, err)
+	require.Equal(t, []client.Coord{{X: 4, Y: 3}}, result.Board.Hazards)
+
+	resultBoard := rules.NewBoardState(11, 11)
+	resultBoard.GameState = result.GameState
+	entities, err := maps.Entities(resultBoard)
+	require.NoError(t, err)
+	require.Len(t, entities, 1)
+	require.Equal(t, []rules.Point{{X: 4, Y: 3}}, entities[0].Body)
+}
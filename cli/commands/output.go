@@ -1,18 +1,94 @@
 package commands
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"sort"
 
+	"github.com/BattlesnakeOfficial/rules"
 	"github.com/BattlesnakeOfficial/rules/client"
 )
 
+// Writes a game to a JSONL file as it's being played. Each line is written
+// as soon as it's available, so tools can follow a game that's still running
+// and the turns played so far are kept if the game is interrupted.
 type GameExporter struct {
-	game          client.Game
-	snakeRequests []client.SnakeRequest
-	winner        SnakeState
-	isDraw        bool
+	output io.Writer
+	lines  int
+}
+
+// Version of the export format written by GameExporter. Files without a
+// version are version 1, which only contain the snake request for each turn.
+const exportSchemaVersion = 2
+
+// The first line of an exported game: the API game object, along with the
+// seed, map version and map parameters needed to reproduce the game locally.
+type exportedGame struct {
+	client.Game
+	Seed          int64 `json:"seed"`
+	SchemaVersion int   `json:"schemaVersion,omitempty"`
+	// The ID of the map including its version, like "sinkholes@1", so the
+	// game can be replayed with the same map after it changes
+	ResolvedMap string            `json:"resolvedMap,omitempty"`
+	MapParams   map[string]string `json:"mapParams,omitempty"`
+}
+
+// A single turn in an exported game. The embedded snake request keeps each
+// line usable as an API request, with `you` set to the first snake on the board.
+type exportedTurn struct {
+	client.SnakeRequest
+	// How each snake responded to its move request for this turn. Empty for the last turn.
+	Moves []exportedMove `json:"moves,omitempty"`
+	// The state the map keeps between turns, which isn't part of the snake request
+	GameState map[string]string `json:"gameState,omitempty"`
+	// The values the map keeps for points on the board, sorted by position
+	PointState []exportedPointState `json:"pointState,omitempty"`
+}
+
+// A single entry of a board's PointState, since JSON objects can only have string keys.
+type exportedPointState struct {
+	X     int `json:"x"`
+	Y     int `json:"y"`
+	Value int `json:"value"`
+}
+
+func exportPointState(pointState map[rules.Point]int) []exportedPointState {
+	var exported []exportedPointState
+	for p, value := range pointState {
+		exported = append(exported, exportedPointState{X: p.X, Y: p.Y, Value: value})
+	}
+	sort.Slice(exported, func(i, j int) bool {
+		if exported[i].X != exported[j].X {
+			return exported[i].X < exported[j].X
+		}
+		return exported[i].Y < exported[j].Y
+	})
+	return exported
+}
+
+// The PointState of a board, from its exported entries.
+func importPointState(exported []exportedPointState) map[rules.Point]int {
+	pointState := make(map[rules.Point]int, len(exported))
+	for _, entry := range exported {
+		pointState[rules.Point{X: entry.X, Y: entry.Y}] = entry.Value
+	}
+	return pointState
+}
+
+// The response of a single snake to a move request.
+type exportedMove struct {
+	ID         string `json:"id"`
+	Move       string `json:"move"` // the move that was applied, which is the previous move if the request failed
+	Shout      string `json:"shout"`
+	Latency    int64  `json:"latency"` // in milliseconds
+	StatusCode int    `json:"statusCode"`
+	Error      string `json:"error,omitempty"`
+	// The exact request sent to the snake, only included when requested
+	Request *client.SnakeRequest `json:"request,omitempty"`
 }
 
 type result struct {
@@ -21,48 +97,145 @@ type result struct {
 	IsDraw     bool   `json:"isDraw"`
 }
 
-func (ge *GameExporter) FlushToFile(outputFile io.Writer) (int, error) {
-	formattedOutput, err := ge.ConvertToJSON()
-	if err != nil {
-		return 0, err
+// Create a GameExporter and write the game line to output.
+func NewGameExporter(output io.Writer, game exportedGame) (*GameExporter, error) {
+	ge := &GameExporter{output: output}
+	game.SchemaVersion = exportSchemaVersion
+	if err := ge.writeLine(game); err != nil {
+		return nil, err
 	}
+	return ge, nil
+}
 
-	for _, line := range formattedOutput {
-		_, err := io.WriteString(outputFile, fmt.Sprintf("%s\n", line))
-		if err != nil {
-			return 0, err
-		}
+// Write a line for a single turn.
+func (ge *GameExporter) AddTurn(turn exportedTurn) error {
+	return ge.writeLine(turn)
+}
+
+// Write the result line. This should only be called once the game has ended normally.
+func (ge *GameExporter) WriteResult(winner SnakeState, isDraw bool) error {
+	return ge.writeLine(result{
+		WinnerID:   winner.ID,
+		WinnerName: winner.Name,
+		IsDraw:     isDraw,
+	})
+}
+
+// Returns the number of lines written so far.
+func (ge *GameExporter) Lines() int {
+	return ge.lines
+}
+
+func (ge *GameExporter) writeLine(value interface{}) error {
+	serialised, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(ge.output, fmt.Sprintf("%s\n", serialised)); err != nil {
+		return err
 	}
+	ge.lines++
+	return nil
+}
 
-	return len(formattedOutput), nil
+// A game read back from a JSONL file written by GameExporter.
+type gameExport struct {
+	Game          client.Game
+	Seed          int64 // zero if the file was written before seeds were exported
+	SchemaVersion int
+	ResolvedMap   string // empty if the file was written before map versions were exported
+	MapParams     map[string]string
+	Turns         []exportedTurn
+	Result        *result // nil if the file doesn't contain a result line, e.g. the game didn't finish
 }
 
-func (ge *GameExporter) ConvertToJSON() ([]string, error) {
-	output := make([]string, 0)
-	serialisedGame, err := json.Marshal(ge.game)
+// Read a JSONL game export from a file on disk.
+func readGameExportFile(path string) (*gameExport, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return output, err
+		return nil, err
 	}
-	output = append(output, string(serialisedGame))
-	for _, board := range ge.snakeRequests {
-		serialisedBoard, err := json.Marshal(board)
-		if err != nil {
-			return output, err
+	defer f.Close()
+
+	return readGameExport(f)
+}
+
+// Read a JSONL game export consisting of a game line, one line for each turn and an optional result line.
+func readGameExport(r io.Reader) (*gameExport, error) {
+	export := &gameExport{}
+
+	scanner := bufio.NewScanner(r)
+	// Turn lines for large boards can easily exceed the default token size
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		lineNumber++
+
+		if lineNumber == 1 {
+			var game exportedGame
+			if err := json.Unmarshal(line, &game); err != nil {
+				return nil, fmt.Errorf("line %d: invalid game: %w", lineNumber, err)
+			}
+			export.Game = game.Game
+			export.Seed = game.Seed
+			export.SchemaVersion = game.SchemaVersion
+			export.ResolvedMap = game.ResolvedMap
+			export.MapParams = game.MapParams
+			if export.SchemaVersion == 0 {
+				export.SchemaVersion = 1
+			}
+			if export.SchemaVersion > exportSchemaVersion {
+				return nil, fmt.Errorf("unsupported schema version %d", export.SchemaVersion)
+			}
+			continue
+		}
+
+		// Lines are distinguished by their keys, since turn lines are snake requests and the last line is a result
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(line, &keys); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
 		}
-		output = append(output, string(serialisedBoard))
+		if _, ok := keys["board"]; ok {
+			var turn exportedTurn
+			if err := json.Unmarshal(line, &turn); err != nil {
+				return nil, fmt.Errorf("line %d: invalid turn: %w", lineNumber, err)
+			}
+			export.Turns = append(export.Turns, turn)
+			continue
+		}
+		if _, ok := keys["isDraw"]; ok {
+			export.Result = &result{}
+			if err := json.Unmarshal(line, export.Result); err != nil {
+				return nil, fmt.Errorf("line %d: invalid result: %w", lineNumber, err)
+			}
+			continue
+		}
+		return nil, fmt.Errorf("line %d: unrecognised line", lineNumber)
 	}
-	serialisedResult, err := json.Marshal(result{
-		WinnerID:   ge.winner.ID,
-		WinnerName: ge.winner.Name,
-		IsDraw:     ge.isDraw,
-	})
-	if err != nil {
-		return output, err
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if lineNumber == 0 {
+		return nil, errors.New("game export is empty")
 	}
-	output = append(output, string(serialisedResult))
-	return output, nil
+	if len(export.Turns) == 0 {
+		return nil, errors.New("game export doesn't contain any turns")
+	}
+
+	return export, nil
 }
 
-func (ge *GameExporter) AddSnakeRequest(snakeRequest client.SnakeRequest) {
-	ge.snakeRequests = append(ge.snakeRequests, snakeRequest)
+// The ID of the map to replay an exported game with, including the version
+// played when it was recorded.
+func (export *gameExport) mapID() string {
+	if export.ResolvedMap != "" {
+		return export.ResolvedMap
+	}
+	return export.Game.Map
 }
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGameExporterStreamsLines(t *testing.T) {
+	var output bytes.Buffer
+
+	gameExporter, err := NewGameExporter(&output, exportedGame{Game: client.Game{ID: "GAME_ID"}, Seed: 42})
+	require.NoError(t, err)
+	require.Equal(t, 1, gameExporter.Lines())
+	require.Equal(t, `{"id":"GAME_ID","ruleset":{"name":"","version":"","settings":{"foodSpawnChance":0,"minimumFood":0,"hazardDamagePerTurn":0,"hazardMap":"","hazardMapAuthor":"","royale":{"shrinkEveryNTurns":0},"squad":{"allowBodyCollisions":false,"sharedElimination":false,"sharedHealth":false,"sharedLength":false}}},"map":"","timeout":0,"source":"","seed":42,"schemaVersion":2}`+"\n", output.String())
+
+	// each turn should be written immediately
+	require.NoError(t, gameExporter.AddTurn(exportedTurn{SnakeRequest: client.SnakeRequest{Turn: 0}}))
+	require.Equal(t, 2, gameExporter.Lines())
+	require.Len(t, strings.Split(strings.TrimSpace(output.String()), "\n"), 2)
+
+	require.NoError(t, gameExporter.AddTurn(exportedTurn{SnakeRequest: client.SnakeRequest{Turn: 1}, Moves: []exportedMove{{ID: "one", Move: "up", Latency: 12, StatusCode: 200}}}))
+	require.NoError(t, gameExporter.WriteResult(SnakeState{ID: "one", Name: "ONE"}, false))
+	require.Equal(t, 4, gameExporter.Lines())
+
+	export, err := readGameExport(&output)
+	require.NoError(t, err)
+	require.Equal(t, int64(42), export.Seed)
+	require.Equal(t, 2, export.SchemaVersion)
+	require.Len(t, export.Turns, 2)
+	require.Empty(t, export.Turns[0].Moves)
+	require.Equal(t, []exportedMove{{ID: "one", Move: "up", Latency: 12, StatusCode: 200}}, export.Turns[1].Moves)
+	require.Equal(t, &result{WinnerID: "one", WinnerName: "ONE"}, export.Result)
+}
+
+func TestGameExporterMap(t *testing.T) {
+	var output bytes.Buffer
+	_, err := NewGameExporter(&output, exportedGame{
+		Game:        client.Game{ID: "GAME_ID", Map: "sinkholes"},
+		Seed:        42,
+		ResolvedMap: "sinkholes@1",
+		MapParams:   map[string]string{"sinkholes.maxRings": "2"},
+	})
+	require.NoError(t, err)
+	require.Contains(t, output.String(), `"resolvedMap":"sinkholes@1","mapParams":{"sinkholes.maxRings":"2"}`)
+
+	output.WriteString(`{"game":{"id":"GAME_ID"},"turn":0,"board":{"height":7,"width":7,"snakes":[],"food":[],"hazards":[]}}` + "\n")
+	export, err := readGameExport(&output)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"sinkholes.maxRings": "2"}, export.MapParams)
+	require.Equal(t, "sinkholes@1", export.mapID())
+
+	// older files only have the map ID
+	export.ResolvedMap = ""
+	require.Equal(t, "sinkholes", export.mapID())
+}
+
+func TestBuildExportedMoves(t *testing.T) {
+	s1 := rules.Snake{ID: "one", Body: []rules.Point{{X: 3, Y: 3}}}
+	s2 := rules.Snake{ID: "two", Body: []rules.Point{{X: 4, Y: 3}}, EliminatedCause: rules.EliminatedByCollision}
+	s3 := rules.Snake{ID: "three", Body: []rules.Point{{X: 5, Y: 3}}}
+	boardState := rules.NewBoardState(11, 11).WithSnakes([]rules.Snake{s1, s2, s3})
+
+	gameState := buildDefaultGameState()
+	gameState.OutputRequests = true
+	require.NoError(t, gameState.Initialize())
+	gameState.snakeStates = map[string]SnakeState{
+		"one":   {ID: "one", URL: "http://example.com/1"},
+		"two":   {ID: "two", URL: "http://example.com/2"},
+		"three": {ID: "three", URL: "http://example.com/3", LastMove: rules.MoveDown},
+	}
+	gameState.httpClient = stubHTTPClient{nil, http.StatusOK, func(url string) string {
+		if url == "http://example.com/1/move" {
+			return `{"move": "left", "shout": "hello"}`
+		}
+		return `{"move": "sideways"}`
+	}, 12 * time.Millisecond}
+
+	for _, id := range []string{"one", "three"} {
+		gameState.snakeStates[id] = gameState.getSnakeUpdate(boardState, gameState.snakeStates[id])
+	}
+
+	// you should always be the first snake that's still alive
+	exportedTurn := gameState.buildExportedTurn(boardState)
+	require.Equal(t, "one", exportedTurn.You.ID)
+
+	moves := gameState.buildExportedMoves(boardState)
+	require.Len(t, moves, 2)
+	require.Equal(t, "one", moves[0].ID)
+	require.Equal(t, rules.MoveLeft, moves[0].Move)
+	require.Equal(t, "hello", moves[0].Shout)
+	require.Equal(t, int64(12), moves[0].Latency)
+	require.Equal(t, http.StatusOK, moves[0].StatusCode)
+	require.NotNil(t, moves[0].Request)
+	require.Equal(t, "one", moves[0].Request.You.ID)
+
+	// invalid moves fall back to the previous move
+	require.Equal(t, "three", moves[1].ID)
+	require.Equal(t, rules.MoveDown, moves[1].Move)
+	require.Equal(t, "three", moves[1].Request.You.ID)
+}
+
+func TestExportedTurnPointState(t *testing.T) {
+	boardState := rules.NewBoardState(7, 7).
+		WithSnakes([]rules.Snake{{ID: "one", Body: []rules.Point{{X: 3, Y: 3}}}}).
+		WithPointState(map[rules.Point]int{{X: 4, Y: 1}: 2, {X: 0, Y: 3}: 7})
+
+	gameState := buildDefaultGameState()
+	require.NoError(t, gameState.Initialize())
+	gameState.snakeStates = map[string]SnakeState{"one": {ID: "one"}}
+
+	turn := gameState.buildExportedTurn(boardState)
+	data, err := json.Marshal(turn)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"pointState":[{"x":0,"y":3,"value":7},{"x":4,"y":1,"value":2}]`)
+
+	var decoded exportedTurn
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, boardState.PointState, boardStateFromTurn(decoded).PointState)
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,10 +11,13 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/BattlesnakeOfficial/rules"
@@ -41,6 +45,10 @@ type SnakeState struct {
 	Error      error
 	StatusCode int
 	Latency    time.Duration
+	Shout      string
+
+	// The last move request sent to the snake, only kept when it needs to be exported
+	lastRequest *client.SnakeRequest
 }
 
 type GameState struct {
@@ -50,31 +58,57 @@ type GameState struct {
 	Names               []string
 	URLs                []string
 	Timeout             int
+	HumanTimeout        int
 	TurnDuration        int
 	Sequential          bool
 	GameType            string
 	MapName             string
+	MapFile             string
+	MapDir              string
 	ViewMap             bool
+	UseTUI              bool
 	UseColor            bool
 	Seed                int64
 	TurnDelay           int
 	OutputPath          string
+	OutputRequests      bool
 	ViewInBrowser       bool
 	BoardURL            string
+	Offline             bool
 	FoodSpawnChance     int
 	MinimumFood         int
 	HazardDamagePerTurn int
 	ShrinkEveryNTurns   int
+	ResumePath          string
+	ResumeTurn          int
+	Faults              []string
+	FaultSeed           int64
+	Params              []string
 
 	// Internal game state
-	settings    map[string]string
-	snakeStates map[string]SnakeState
-	gameID      string
-	httpClient  TimedHttpClient
-	ruleset     rules.Ruleset
-	gameMap     maps.GameMap
-	outputFile  io.WriteCloser
-	idGenerator func(int) string
+	settings         map[string]string
+	settingsSet      map[string]bool   // settings given on the command line, which override the map's recommended settings
+	mapParams        map[string]string // parameters for the map, from --param
+	snakeStates      map[string]SnakeState
+	gameID           string
+	httpClient       TimedHttpClient
+	ruleset          rules.Ruleset
+	gameMap          maps.GameMap
+	outputFile       io.WriteCloser
+	idGenerator      func(int) string
+	resumeBoardState *rules.BoardState
+	keyboard         *keyboardInput
+	faultSpecs       []faultSpec
+	moveHTTPClients  map[string]TimedHttpClient
+	snakeOrder       []string // snake IDs in the order they were given
+}
+
+// The play flags for ruleset settings, and the settings they set.
+var settingFlags = map[string]string{
+	"foodSpawnChance":     rules.ParamFoodSpawnChance,
+	"minimumFood":         rules.ParamMinimumFood,
+	"hazardDamagePerTurn": rules.ParamHazardDamagePerTurn,
+	"shrinkEveryNTurns":   rules.ParamShrinkEveryNTurns,
 }
 
 func NewPlayCommand() *cobra.Command {
@@ -85,6 +119,11 @@ func NewPlayCommand() *cobra.Command {
 		Short: "Play a game of Battlesnake locally.",
 		Long:  "Play a game of Battlesnake locally.",
 		Run: func(cmd *cobra.Command, args []string) {
+			for flag, param := range settingFlags {
+				if cmd.Flags().Changed(flag) {
+					gameState.markSettingSet(param)
+				}
+			}
 			if err := gameState.Initialize(); err != nil {
 				log.ERROR.Fatalf("Error initializing game: %v", err)
 			}
@@ -99,22 +138,35 @@ func NewPlayCommand() *cobra.Command {
 	playCmd.Flags().StringArrayVarP(&gameState.Names, "name", "n", nil, "Name of Snake")
 	playCmd.Flags().StringArrayVarP(&gameState.URLs, "url", "u", nil, "URL of Snake")
 	playCmd.Flags().IntVarP(&gameState.Timeout, "timeout", "t", 500, "Request Timeout")
+	playCmd.Flags().IntVar(&gameState.HumanTimeout, "human-timeout", 2000, "Time in milliseconds to choose a move for a snake with the URL \""+humanSnakeURL+"\", which is controlled from the keyboard")
 	playCmd.Flags().BoolVarP(&gameState.Sequential, "sequential", "s", false, "Use Sequential Processing")
 	playCmd.Flags().StringVarP(&gameState.GameType, "gametype", "g", "standard", "Type of Game Rules")
-	playCmd.Flags().StringVarP(&gameState.MapName, "map", "m", "standard", "Game map to use to populate the board")
+	playCmd.Flags().StringVarP(&gameState.MapName, "map", "m", "standard", "Game map to use to populate the board. Join map IDs with + to combine them, e.g. royale+healing_pools")
+	playCmd.Flags().StringVar(&gameState.MapFile, "map-file", "", "JSON or YAML file with a custom map to use to populate the board, instead of --map")
+	playCmd.Flags().StringVar(&gameState.MapDir, "map-dir", "", "Directory of JSON or YAML custom map files, which can be chosen with --map")
 	playCmd.Flags().BoolVarP(&gameState.ViewMap, "viewmap", "v", false, "View the Map Each Turn")
+	playCmd.Flags().BoolVar(&gameState.UseTUI, "tui", false, "Step through the game in a full-screen terminal UI")
 	playCmd.Flags().BoolVarP(&gameState.UseColor, "color", "c", false, "Use color to draw the map")
 	playCmd.Flags().Int64VarP(&gameState.Seed, "seed", "r", time.Now().UTC().UnixNano(), "Random Seed")
 	playCmd.Flags().IntVarP(&gameState.TurnDelay, "delay", "d", 0, "Turn Delay in Milliseconds")
 	playCmd.Flags().IntVarP(&gameState.TurnDuration, "duration", "D", 0, "Minimum Turn Duration in Milliseconds")
 	playCmd.Flags().StringVarP(&gameState.OutputPath, "output", "o", "", "File path to output game state to. Existing files will be overwritten")
+	playCmd.Flags().BoolVar(&gameState.OutputRequests, "output-requests", false, "Include the exact move request sent to each snake in the output file")
 	playCmd.Flags().BoolVar(&gameState.ViewInBrowser, "browser", false, "View the game in the browser using the Battlesnake game board")
 	playCmd.Flags().StringVar(&gameState.BoardURL, "board-url", "https://board.battlesnake.com", "Base URL for the game board when using --browser")
+	playCmd.Flags().BoolVar(&gameState.Offline, "offline", false, "Use the board viewer built into the CLI when using --browser, instead of the Battlesnake game board")
 
 	playCmd.Flags().IntVar(&gameState.FoodSpawnChance, "foodSpawnChance", 15, "Percentage chance of spawning a new food every round")
 	playCmd.Flags().IntVar(&gameState.MinimumFood, "minimumFood", 1, "Minimum food to keep on the board every turn")
 	playCmd.Flags().IntVar(&gameState.HazardDamagePerTurn, "hazardDamagePerTurn", 14, "Health damage a snake will take when ending its turn in a hazard")
 	playCmd.Flags().IntVar(&gameState.ShrinkEveryNTurns, "shrinkEveryNTurns", 25, "In Royale mode, the number of turns between generating new hazards")
+	playCmd.Flags().StringArrayVar(&gameState.Params, "param", nil, "Set a parameter of the map, in the form key=value. Use the map info command to list a map's parameters")
+
+	playCmd.Flags().StringVar(&gameState.ResumePath, "resume", "", "Resume a game from a file written with --output, or from a JSON serialized BoardState")
+	playCmd.Flags().IntVar(&gameState.ResumeTurn, "resume-turn", -1, "Turn to resume from when using --resume with a file written with --output (default is the last recorded turn)")
+
+	playCmd.Flags().StringArrayVar(&gameState.Faults, "fault", nil, "Simulate network faults in move requests, in the form [snake name:]delay=0.2,max-delay=300,drop=0.05,error=0.05,truncate=0.05")
+	playCmd.Flags().Int64Var(&gameState.FaultSeed, "fault-seed", 0, "Random Seed used to choose when faults happen (default is the game seed)")
 
 	playCmd.Flags().SortFlags = false
 
@@ -136,6 +188,55 @@ func (gameState *GameState) Initialize() error {
 		},
 	}
 
+	// Parse the network faults to simulate
+	gameState.faultSpecs = nil
+	for _, fault := range gameState.Faults {
+		spec, err := parseFaultSpec(fault)
+		if err != nil {
+			return fmt.Errorf("Invalid fault %#v: %w", fault, err)
+		}
+		gameState.faultSpecs = append(gameState.faultSpecs, spec)
+	}
+	if gameState.FaultSeed == 0 {
+		gameState.FaultSeed = gameState.Seed
+	}
+
+	// Read moves from the keyboard for a snake controlled by a person
+	humanSnakes := 0
+	for _, snakeURL := range gameState.URLs {
+		if snakeURL == humanSnakeURL {
+			humanSnakes++
+		}
+	}
+	if humanSnakes > 1 {
+		return fmt.Errorf("Only one snake can be controlled from the keyboard")
+	}
+	if humanSnakes == 1 {
+		if gameState.UseTUI {
+			return fmt.Errorf("A snake controlled from the keyboard can't be used with --tui")
+		}
+		if gameState.HumanTimeout <= 0 {
+			gameState.HumanTimeout = 2000
+		}
+		gameState.keyboard = newKeyboardInput(os.Stdin, true)
+	}
+
+	// Load the game to resume first, because it overrides the game options
+	if gameState.ResumePath != "" {
+		if err := gameState.loadResumeState(); err != nil {
+			return fmt.Errorf("Failed to load game to resume: %w", err)
+		}
+	}
+
+	// Load custom maps before looking up the map to play
+	mapFileID, err := loadCustomMaps(gameState.MapDir, gameState.MapFile)
+	if err != nil {
+		return err
+	}
+	if mapFileID != "" {
+		gameState.MapName = mapFileID
+	}
+
 	// Load game map
 	gameMap, err := maps.GetMap(gameState.MapName)
 	if err != nil {
@@ -143,13 +244,31 @@ func (gameState *GameState) Initialize() error {
 	}
 	gameState.gameMap = gameMap
 
-	// Create settings object
+	// Create settings object, using the map's recommended settings unless they were given on the command line
 	gameState.settings = map[string]string{
 		rules.ParamFoodSpawnChance:     fmt.Sprint(gameState.FoodSpawnChance),
 		rules.ParamMinimumFood:         fmt.Sprint(gameState.MinimumFood),
 		rules.ParamHazardDamagePerTurn: fmt.Sprint(gameState.HazardDamagePerTurn),
 		rules.ParamShrinkEveryNTurns:   fmt.Sprint(gameState.ShrinkEveryNTurns),
 	}
+	meta := gameMap.Meta()
+	for key, value := range meta.DefaultSettings {
+		if !gameState.settingsSet[key] {
+			gameState.settings[key] = value
+		}
+	}
+
+	// Map parameters are passed to the map with the rest of the settings
+	gameState.mapParams, err = parseMapParams(gameState.Params)
+	if err != nil {
+		return err
+	}
+	if err := meta.ValidateParams(gameState.mapParams); err != nil {
+		return fmt.Errorf("Invalid map parameter: %w", err)
+	}
+	for key, value := range gameState.mapParams {
+		gameState.settings[key] = value
+	}
 
 	// Build ruleset from settings
 	ruleset := rules.NewRulesetBuilder().
@@ -173,33 +292,86 @@ func (gameState *GameState) Initialize() error {
 	return nil
 }
 
+// Record that a setting was chosen, so the map's recommended value isn't used instead.
+func (gameState *GameState) markSettingSet(key string) {
+	if gameState.settingsSet == nil {
+		gameState.settingsSet = map[string]bool{}
+	}
+	gameState.settingsSet[key] = true
+}
+
+// Parse map parameters in the form key=value.
+func parseMapParams(params []string) (map[string]string, error) {
+	result := map[string]string{}
+	for _, param := range params {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("Invalid map parameter %#v, expected key=value", param)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
 // Setup and run a full game.
 func (gameState *GameState) Run() error {
+	_, err := gameState.playGame()
+	return err
+}
+
+// The outcome of a game that was played to the end.
+type gameResult struct {
+	Turns  int
+	Winner SnakeState // empty when no snake won
+	IsDraw bool
+}
+
+// Play a full game, and return its result. The result is nil when the game is interrupted.
+func (gameState *GameState) playGame() (*gameResult, error) {
 	var gameOver bool
 	var err error
 
 	// Setup local state for snakes
 	gameState.snakeStates, err = gameState.buildSnakesFromOptions()
 	if err != nil {
-		return fmt.Errorf("Error getting snake metadata: %w", err)
+		return nil, fmt.Errorf("Error getting snake metadata: %w", err)
+	}
+	gameState.moveHTTPClients, err = gameState.buildMoveHTTPClients()
+	if err != nil {
+		return nil, err
 	}
 
 	rand.Seed(gameState.Seed)
 
 	gameOver, boardState, err := gameState.initializeBoardFromArgs()
 	if err != nil {
-		return fmt.Errorf("Error initializing board: %w", err)
+		return nil, fmt.Errorf("Error initializing board: %w", err)
 	}
 
-	gameExporter := GameExporter{
-		game:          gameState.createClientGame(),
-		snakeRequests: make([]client.SnakeRequest, 0),
-		winner:        SnakeState{},
-		isDraw:        false,
-	}
+	var gameExporter *GameExporter
 	exportGame := gameState.outputFile != nil
 	if exportGame {
 		defer gameState.outputFile.Close()
+
+		gameExporter, err = NewGameExporter(gameState.outputFile, exportedGame{
+			Game:        gameState.createClientGame(),
+			Seed:        gameState.Seed,
+			ResolvedMap: maps.ResolvedMapID(gameState.gameMap),
+			MapParams:   gameState.mapParams,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Unable to export game: %w", err)
+		}
+	}
+
+	// Stop the game cleanly when interrupted, so that the turns played so far are kept in the output file.
+	interrupted, stopInterrupts := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopInterrupts()
+
+	// The terminal reads single key presses for the whole game, and is restored
+	// when the game ends or is interrupted
+	if gameState.keyboard != nil {
+		defer gameState.keyboard.start()()
 	}
 
 	boardGame := board.Game{
@@ -219,12 +391,12 @@ func (gameState *GameState) Run() error {
 	if gameState.ViewInBrowser {
 		serverURL, err := boardServer.Listen()
 		if err != nil {
-			return fmt.Errorf("Error starting HTTP server: %w", err)
+			return nil, fmt.Errorf("Error starting HTTP server: %w", err)
 		}
 		defer boardServer.Shutdown()
 		log.INFO.Printf("Board server listening on %s", serverURL)
 
-		boardURL := fmt.Sprintf(gameState.BoardURL+"?engine=%s&game=%s&autoplay=true", serverURL, gameState.gameID)
+		boardURL := browserBoardURL(gameState.BoardURL, serverURL, gameState.gameID, gameState.Offline)
 
 		log.INFO.Printf("Opening board URL: %s", boardURL)
 		if err := browser.OpenURL(boardURL); err != nil {
@@ -237,37 +409,74 @@ func (gameState *GameState) Run() error {
 
 	log.INFO.Printf("Ruleset: %v, Seed: %v", gameState.GameType, gameState.Seed)
 
-	if gameState.ViewMap {
-		gameState.printMap(boardState)
-	} else {
-		gameState.printState(boardState)
-	}
-
-	// Export game first, if enabled, so that we capture the request for turn zero.
-	if exportGame {
-		// The output file was designed in a way so that (nearly) every entry is equivalent to a valid API request.
-		// This is meant to help unlock further development of tools such as replaying a saved game by simply copying each line and sending it as a POST request.
-		// There was a design choice to be made here: the difference between SnakeRequest and BoardState is the `you` key.
-		// We could choose to either store the SnakeRequest of each snake OR to omit the `you` key OR fill the `you` key with one of the snakes
-		// In all cases the API request is technically non-compliant with how the actual API request should be.
-		// The third option (filling the `you` key with an arbitrary snake) is the closest to the actual API request that would need the least manipulation to
-		// be adjusted to look like an API call for a specific snake in the game.
-		for _, snakeState := range gameState.snakeStates {
-			snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
-			gameExporter.AddSnakeRequest(snakeRequest)
-			break
+	var ui *terminalUI
+	if gameState.UseTUI {
+		ui = newTerminalUI(gameState.UseColor, 0)
+		if err := ui.Start(); err != nil {
+			return nil, err
 		}
+		go ui.Run()
+		defer ui.Close()
+
+		// Quitting the UI stops the game the same way as interrupting it
+		var cancel context.CancelFunc
+		interrupted, cancel = context.WithCancel(interrupted)
+		defer cancel()
+		go func() {
+			select {
+			case <-ui.Done():
+				cancel()
+			case <-interrupted.Done():
+			}
+		}()
 	}
 
+	gameState.showBoard(ui, boardState)
+
 	var endTime time.Time
 	for !gameOver {
+		// Hold the game before the next move requests while it's paused in the browser
+		if gameState.ViewInBrowser {
+			_ = boardServer.WaitWhilePaused(interrupted, gameState.gameID)
+		}
+
+		if interrupted.Err() != nil {
+			// Restore the default behaviour, so that interrupting again kills the process
+			stopInterrupts()
+			if ui != nil {
+				ui.Close()
+			}
+			log.INFO.Printf("Game interrupted after %v turns.", boardState.Turn)
+			if exportGame {
+				if err := gameExporter.AddTurn(gameState.buildExportedTurn(boardState)); err != nil {
+					return nil, fmt.Errorf("Unable to export game: %w", err)
+				}
+				log.INFO.Printf("Wrote %d lines to output file: %s", gameExporter.Lines(), gameState.OutputPath)
+			}
+			return nil, nil
+		}
+
 		if gameState.TurnDuration > 0 {
 			endTime = time.Now().Add(time.Duration(gameState.TurnDuration) * time.Millisecond)
 		}
 
+		// Each turn is exported once the moves made on that turn are known. The
+		// moves are left out when the game is over, since they aren't applied.
+		exportedTurn := gameState.buildExportedTurn(boardState)
+		previousBoardState := boardState
+
 		gameOver, boardState, err = gameState.createNextBoardState(boardState)
 		if err != nil {
-			return fmt.Errorf("Error processing game: %w", err)
+			return nil, fmt.Errorf("Error processing game: %w", err)
+		}
+
+		if exportGame {
+			if !gameOver {
+				exportedTurn.Moves = gameState.buildExportedMoves(previousBoardState)
+			}
+			if err := gameExporter.AddTurn(exportedTurn); err != nil {
+				return nil, fmt.Errorf("Unable to export game: %w", err)
+			}
 		}
 
 		if gameOver {
@@ -275,11 +484,7 @@ func (gameState *GameState) Run() error {
 			break
 		}
 
-		if gameState.ViewMap {
-			gameState.printMap(boardState)
-		} else {
-			gameState.printState(boardState)
-		}
+		gameState.showBoard(ui, boardState)
 
 		if gameState.TurnDelay > 0 {
 			time.Sleep(time.Duration(gameState.TurnDelay) * time.Millisecond)
@@ -292,41 +497,43 @@ func (gameState *GameState) Run() error {
 		if gameState.ViewInBrowser {
 			boardServer.SendEvent(gameState.buildFrameEvent(boardState))
 		}
-
-		if exportGame {
-			for _, snakeState := range gameState.snakeStates {
-				snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
-				gameExporter.AddSnakeRequest(snakeRequest)
-				break
-			}
-		}
 	}
 
-	gameExporter.isDraw = false
+	isDraw := false
+	var winner SnakeState
 
 	if len(gameState.snakeStates) > 1 {
 		// A draw is possible if there is more than one snake in the game.
-		gameExporter.isDraw = true
+		isDraw = true
 	}
 
 	for _, snake := range boardState.Snakes {
 		snakeState := gameState.snakeStates[snake.ID]
 		if snake.EliminatedCause == rules.NotEliminated {
-			gameExporter.isDraw = false
-			gameExporter.winner = snakeState
+			isDraw = false
+			winner = snakeState
 		}
 
 		gameState.sendEndRequest(boardState, snakeState)
 	}
 
-	if gameExporter.isDraw {
-		log.INFO.Printf("Game completed after %v turns. It was a draw.", boardState.Turn)
-	} else if gameExporter.winner.Name != "" {
-		log.INFO.Printf("Game completed after %v turns. %v was the winner.", boardState.Turn, gameExporter.winner.Name)
+	var result string
+	if isDraw {
+		result = fmt.Sprintf("Game completed after %v turns. It was a draw.", boardState.Turn)
+	} else if winner.Name != "" {
+		result = fmt.Sprintf("Game completed after %v turns. %v was the winner.", boardState.Turn, winner.Name)
 	} else {
-		log.INFO.Printf("Game completed after %v turns.", boardState.Turn)
+		result = fmt.Sprintf("Game completed after %v turns.", boardState.Turn)
 	}
 
+	if ui != nil {
+		// Leave the game open in the UI until the user quits
+		ui.SetStatus(result)
+		<-ui.Done()
+		ui.Close()
+	}
+	log.INFO.Print(result)
+
 	if gameState.ViewInBrowser {
 		boardServer.SendEvent(board.GameEvent{
 			EventType: board.EVENT_TYPE_GAME_END,
@@ -335,31 +542,92 @@ func (gameState *GameState) Run() error {
 	}
 
 	if exportGame {
-		lines, err := gameExporter.FlushToFile(gameState.outputFile)
-		if err != nil {
-			return fmt.Errorf("Unable to export game: %w", err)
+		if err := gameExporter.WriteResult(winner, isDraw); err != nil {
+			return nil, fmt.Errorf("Unable to export game: %w", err)
 		}
-		log.INFO.Printf("Wrote %d lines to output file: %s", lines, gameState.OutputPath)
+		log.INFO.Printf("Wrote %d lines to output file: %s", gameExporter.Lines(), gameState.OutputPath)
 	}
 
-	return nil
+	return &gameResult{Turns: boardState.Turn, Winner: winner, IsDraw: isDraw}, nil
 }
 
-func (gameState *GameState) initializeBoardFromArgs() (bool, *rules.BoardState, error) {
-	snakeIds := []string{}
-	for _, snakeState := range gameState.snakeStates {
-		snakeIds = append(snakeIds, snakeState.ID)
+// Build the line written to the output file for a turn.
+//
+// The output file was designed in a way so that (nearly) every entry is equivalent to a valid API request.
+// This is meant to help unlock further development of tools such as replaying a saved game by simply copying each line and sending it as a POST request.
+// The difference between SnakeRequest and BoardState is the `you` key, which is filled with the first snake on the board.
+// The exact request sent to each snake can be included with the moves instead.
+func (gameState *GameState) buildExportedTurn(boardState *rules.BoardState) exportedTurn {
+	var youSnake SnakeState
+	for _, snake := range boardState.Snakes {
+		if snake.EliminatedCause == rules.NotEliminated {
+			youSnake = gameState.snakeStates[snake.ID]
+			break
+		}
 	}
-	boardState, err := maps.SetupBoard(gameState.gameMap.ID(), gameState.ruleset.Settings(), gameState.Width, gameState.Height, snakeIds)
-	if err != nil {
-		return false, nil, fmt.Errorf("Error initializing BoardState with map: %w", err)
+	if youSnake.ID == "" && len(boardState.Snakes) > 0 {
+		youSnake = gameState.snakeStates[boardState.Snakes[0].ID]
 	}
-	gameOver, boardState, err := gameState.ruleset.Execute(boardState, nil)
-	if err != nil {
-		return false, nil, fmt.Errorf("Error initializing BoardState with ruleset: %w", err)
+
+	turn := exportedTurn{
+		SnakeRequest: gameState.getRequestBodyForSnake(boardState, youSnake),
+	}
+	if len(boardState.GameState) > 0 {
+		turn.GameState = make(map[string]string, len(boardState.GameState))
+		for key, value := range boardState.GameState {
+			turn.GameState[key] = value
+		}
+	}
+	turn.PointState = exportPointState(boardState.PointState)
+	return turn
+}
+
+// Build the record of how each snake that was alive on a turn responded to its move request.
+func (gameState *GameState) buildExportedMoves(boardState *rules.BoardState) []exportedMove {
+	var moves []exportedMove
+	for _, snake := range boardState.Snakes {
+		if snake.EliminatedCause != rules.NotEliminated {
+			continue
+		}
+		snakeState := gameState.snakeStates[snake.ID]
+		move := exportedMove{
+			ID:         snakeState.ID,
+			Move:       snakeState.LastMove,
+			Shout:      snakeState.Shout,
+			Latency:    snakeState.Latency.Milliseconds(),
+			StatusCode: snakeState.StatusCode,
+			Request:    snakeState.lastRequest,
+		}
+		if snakeState.Error != nil {
+			move.Error = snakeState.Error.Error()
+		}
+		moves = append(moves, move)
+	}
+	return moves
+}
+
+func (gameState *GameState) initializeBoardFromArgs() (bool, *rules.BoardState, error) {
+	var gameOver bool
+	var boardState *rules.BoardState
+	var err error
+	if gameState.resumeBoardState != nil {
+		log.INFO.Printf("Resuming game from turn %d", gameState.resumeBoardState.Turn)
+		boardState = gameState.resumeBoardState.Clone()
+	} else {
+		boardState, err = maps.SetupBoardWithMap(gameState.gameMap, gameState.ruleset.Settings(), gameState.Width, gameState.Height, gameState.orderedSnakeIDs())
+		if err != nil {
+			return false, nil, fmt.Errorf("Error initializing BoardState with map: %w", err)
+		}
+		gameOver, boardState, err = gameState.ruleset.Execute(boardState, nil)
+		if err != nil {
+			return false, nil, fmt.Errorf("Error initializing BoardState with ruleset: %w", err)
+		}
 	}
 
 	for _, snakeState := range gameState.snakeStates {
+		if isHumanSnake(snakeState) {
+			continue
+		}
 		snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
 		requestBody := serialiseSnakeRequest(snakeRequest)
 		u, _ := url.ParseRequestURI(snakeState.URL)
@@ -395,9 +663,19 @@ func (gameState *GameState) createNextBoardState(boardState *rules.BoardState) (
 	} else {
 		var wg sync.WaitGroup
 
+		// The snake controlled from the keyboard chooses its move once the other
+		// snakes have responded, so that the board and prompt aren't mixed up
+		// with their logs
+		var humanSnakeState *SnakeState
 		for _, snakeState := range gameState.snakeStates {
 			for _, snake := range boardState.Snakes {
-				if snakeState.ID == snake.ID && snake.EliminatedCause == rules.NotEliminated {
+				if snakeState.ID != snake.ID || snake.EliminatedCause != rules.NotEliminated {
+					continue
+				}
+				if isHumanSnake(snakeState) {
+					human := snakeState
+					humanSnakeState = &human
+				} else {
 					wg.Add(1)
 					go func(snakeState SnakeState) {
 						defer wg.Done()
@@ -409,6 +687,9 @@ func (gameState *GameState) createNextBoardState(boardState *rules.BoardState) (
 		}
 
 		wg.Wait()
+		if humanSnakeState != nil {
+			stateUpdates <- gameState.getSnakeUpdate(boardState, *humanSnakeState)
+		}
 		close(stateUpdates)
 	}
 
@@ -435,12 +716,20 @@ func (gameState *GameState) createNextBoardState(boardState *rules.BoardState) (
 }
 
 func (gameState *GameState) getSnakeUpdate(boardState *rules.BoardState, snakeState SnakeState) SnakeState {
+	if isHumanSnake(snakeState) {
+		return gameState.getHumanSnakeUpdate(boardState, snakeState)
+	}
+
 	snakeState.StatusCode = 0
 	snakeState.Error = nil
 	snakeState.Latency = 0
+	snakeState.Shout = ""
 
 	snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
 	requestBody := serialiseSnakeRequest(snakeRequest)
+	if gameState.OutputRequests {
+		snakeState.lastRequest = &snakeRequest
+	}
 
 	u, err := url.ParseRequestURI(snakeState.URL)
 	if err != nil {
@@ -450,7 +739,7 @@ func (gameState *GameState) getSnakeUpdate(boardState *rules.BoardState, snakeSt
 	}
 	u.Path = path.Join(u.Path, "move")
 	log.DEBUG.Printf("POST %s: %v", u, string(requestBody))
-	res, responseTime, err := gameState.httpClient.Post(u.String(), "application/json", bytes.NewBuffer(requestBody))
+	res, responseTime, err := gameState.moveHTTPClient(snakeState).Post(u.String(), "application/json", bytes.NewBuffer(requestBody))
 
 	snakeState.Latency = responseTime
 
@@ -508,11 +797,15 @@ func (gameState *GameState) getSnakeUpdate(boardState *rules.BoardState, snakeSt
 	}
 
 	snakeState.LastMove = playerResponse.Move
+	snakeState.Shout = playerResponse.Shout
 
 	return snakeState
 }
 
 func (gameState *GameState) sendEndRequest(boardState *rules.BoardState, snakeState SnakeState) {
+	if isHumanSnake(snakeState) {
+		return
+	}
 	snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
 	requestBody := serialiseSnakeRequest(snakeRequest)
 	u, _ := url.ParseRequestURI(snakeState.URL)
@@ -550,12 +843,13 @@ func (gameState *GameState) createClientGame() client.Game {
 			Version:  "cli", // TODO: Use GitHub Release Version
 			Settings: client.ConvertRulesetSettings(gameState.ruleset.Settings()),
 		},
-		Map: gameState.gameMap.ID(),
+		Map: maps.GameMapID(gameState.gameMap),
 	}
 }
 
 func (gameState *GameState) buildSnakesFromOptions() (map[string]SnakeState, error) {
 	bodyChars := []rune{'■', '⌀', '●', '☻', '◘', '☺', '□', '⍟'}
+	gameState.snakeOrder = nil
 	var numSnakes int
 	snakes := map[string]SnakeState{}
 	numNames := len(gameState.Names)
@@ -583,6 +877,17 @@ func (gameState *GameState) buildSnakesFromOptions() (map[string]SnakeState, err
 			snakeName = GenerateSnakeName()
 		}
 
+		if i < numURLs && gameState.URLs[i] == humanSnakeURL {
+			snakeState := SnakeState{
+				Name: snakeName, URL: humanSnakeURL, ID: id, LastMove: "up", Character: bodyChars[i%8], Color: humanSnakeColor, StatusCode: http.StatusOK,
+			}
+			snakes[snakeState.ID] = snakeState
+			gameState.snakeOrder = append(gameState.snakeOrder, snakeState.ID)
+
+			log.INFO.Printf("Snake ID: %v controlled from the keyboard, Name: \"%v\"", snakeState.ID, snakeState.Name)
+			continue
+		}
+
 		if i < numURLs {
 			u, err := url.ParseRequestURI(gameState.URLs[i])
 			if err != nil {
@@ -631,12 +936,59 @@ func (gameState *GameState) buildSnakesFromOptions() (map[string]SnakeState, err
 		}
 
 		snakes[snakeState.ID] = snakeState
+		gameState.snakeOrder = append(gameState.snakeOrder, snakeState.ID)
 
 		log.INFO.Printf("Snake ID: %v URL: %v, Name: \"%v\"", snakeState.ID, snakeURL, snakeState.Name)
 	}
 	return snakes, nil
 }
 
+// Wrap the HTTP client used for each snake's move requests to simulate the
+// network faults asked for.
+func (gameState *GameState) buildMoveHTTPClients() (map[string]TimedHttpClient, error) {
+	snakeNames := map[string]bool{}
+	for _, snakeState := range gameState.snakeStates {
+		snakeNames[snakeState.Name] = true
+	}
+	for _, spec := range gameState.faultSpecs {
+		if spec.snakeName != "" && !snakeNames[spec.snakeName] {
+			return nil, fmt.Errorf("Faults were given for %#v, but there's no snake with that name", spec.snakeName)
+		}
+	}
+
+	clients := map[string]TimedHttpClient{}
+	for _, snakeState := range gameState.snakeStates {
+		config, ok := faultsForSnake(gameState.faultSpecs, snakeState.Name)
+		if !ok || isHumanSnake(snakeState) {
+			continue
+		}
+		clients[snakeState.ID] = newFaultInjectingClient(gameState.httpClient, config, time.Duration(gameState.Timeout)*time.Millisecond, gameState.FaultSeed, snakeState.Name)
+		log.INFO.Printf("Simulating network faults for %v: %+v", snakeState.Name, config)
+	}
+	return clients, nil
+}
+
+func (gameState *GameState) moveHTTPClient(snakeState SnakeState) TimedHttpClient {
+	if httpClient, ok := gameState.moveHTTPClients[snakeState.ID]; ok {
+		return httpClient
+	}
+	return gameState.httpClient
+}
+
+// The IDs of the snakes, in the order they were given on the command-line, so
+// that the same seed always puts each snake in the same place.
+func (gameState *GameState) orderedSnakeIDs() []string {
+	if len(gameState.snakeOrder) == len(gameState.snakeStates) {
+		return gameState.snakeOrder
+	}
+	snakeIDs := make([]string, 0, len(gameState.snakeStates))
+	for id := range gameState.snakeStates {
+		snakeIDs = append(snakeIDs, id)
+	}
+	sort.Strings(snakeIDs)
+	return snakeIDs
+}
+
 func (gameState *GameState) printState(boardState *rules.BoardState) {
 	var aliveSnakeNames []string
 	for _, snake := range boardState.Snakes {
@@ -650,9 +1002,56 @@ func (gameState *GameState) printState(boardState *rules.BoardState) {
 	)
 }
 
+// Show a turn in the terminal UI when it's enabled, or otherwise print it.
+func (gameState *GameState) showBoard(ui *terminalUI, boardState *rules.BoardState) {
+	if ui != nil {
+		ui.AddTurn(boardState, gameState.snakeStates)
+	} else if gameState.ViewMap {
+		gameState.printMap(boardState)
+	} else {
+		gameState.printState(boardState)
+	}
+}
+
 func (gameState *GameState) printMap(boardState *rules.BoardState) {
 	var o bytes.Buffer
 	o.WriteString(fmt.Sprintf("Turn: %d\n", boardState.Turn))
+	if gameState.UseColor {
+		o.WriteString(fmt.Sprintf("Hazards "+TERM_BG_GRAY+" "+TERM_RESET+": %v\n", boardState.Hazards))
+	} else {
+		o.WriteString(fmt.Sprintf("Hazards ░: %v\n", boardState.Hazards))
+	}
+	if gameState.UseColor {
+		o.WriteString(fmt.Sprintf("Food "+TERM_FG_FOOD+TERM_BG_WHITE+"●"+TERM_RESET+": %v\n", boardState.Food))
+	} else {
+		o.WriteString(fmt.Sprintf("Food ⚕: %v\n", boardState.Food))
+	}
+	for _, s := range boardState.Snakes {
+		state := gameState.snakeStates[s.ID]
+
+		if gameState.UseColor {
+			red, green, blue := parseSnakeColor(state.Color)
+			o.WriteString(fmt.Sprintf("%v "+TERM_FG_RGB+TERM_BG_WHITE+"■■■"+TERM_RESET+": ", state.Name, red, green, blue))
+		} else {
+			o.WriteString(fmt.Sprintf("%v %c: ", state.Name, state.Character))
+		}
+		o.WriteString(fmt.Sprintf("Health: %d", s.Health))
+		if s.EliminatedCause != rules.NotEliminated {
+			o.WriteString(fmt.Sprintf(", Eliminated: %v, Turn: %d", s.EliminatedCause, s.EliminatedOnTurn))
+		}
+		o.WriteString("\n")
+	}
+	for _, row := range gameState.renderBoard(boardState, false) {
+		o.WriteString(row)
+		o.WriteString("\n")
+	}
+	fmt.Println(o.String())
+}
+
+// Render the board as rows of text, from the top of the board down. When
+// highlightEliminations is set, snakes eliminated on this turn are drawn with
+// crosses.
+func (gameState *GameState) renderBoard(boardState *rules.BoardState, highlightEliminations bool) []string {
 	board := make([][]string, boardState.Width)
 	for i := range board {
 		board[i] = make([]string, boardState.Height)
@@ -673,11 +1072,6 @@ func (gameState *GameState) printMap(boardState *rules.BoardState) {
 			board[oob.X][oob.Y] = "░"
 		}
 	}
-	if gameState.UseColor {
-		o.WriteString(fmt.Sprintf("Hazards "+TERM_BG_GRAY+" "+TERM_RESET+": %v\n", boardState.Hazards))
-	} else {
-		o.WriteString(fmt.Sprintf("Hazards ░: %v\n", boardState.Hazards))
-	}
 	for _, f := range boardState.Food {
 		if gameState.UseColor {
 			board[f.X][f.Y] = TERM_FG_FOOD + "●"
@@ -685,48 +1079,41 @@ func (gameState *GameState) printMap(boardState *rules.BoardState) {
 			board[f.X][f.Y] = "⚕"
 		}
 	}
-	if gameState.UseColor {
-		o.WriteString(fmt.Sprintf("Food "+TERM_FG_FOOD+TERM_BG_WHITE+"●"+TERM_RESET+": %v\n", boardState.Food))
-	} else {
-		o.WriteString(fmt.Sprintf("Food ⚕: %v\n", boardState.Food))
-	}
 	for _, s := range boardState.Snakes {
 		state := gameState.snakeStates[s.ID]
+		eliminatedNow := highlightEliminations && s.EliminatedCause != rules.NotEliminated && s.EliminatedOnTurn == boardState.Turn
 
 		red, green, blue := parseSnakeColor(state.Color)
 		for _, b := range s.Body {
 			if b.X >= 0 && b.X < boardState.Width && b.Y >= 0 && b.Y < boardState.Height {
-				if gameState.UseColor {
+				if eliminatedNow && gameState.UseColor {
+					board[b.X][b.Y] = TERM_FG_ELIMINATED + "✕"
+				} else if eliminatedNow {
+					board[b.X][b.Y] = "✕"
+				} else if gameState.UseColor {
 					board[b.X][b.Y] = fmt.Sprintf(TERM_FG_RGB+"■", red, green, blue)
 				} else {
 					board[b.X][b.Y] = string(state.Character)
 				}
 			}
 		}
-		if gameState.UseColor {
-			o.WriteString(fmt.Sprintf("%v "+TERM_FG_RGB+TERM_BG_WHITE+"■■■"+TERM_RESET+": ", state.Name, red, green, blue))
-		} else {
-			o.WriteString(fmt.Sprintf("%v %c: ", state.Name, state.Character))
-		}
-		o.WriteString(fmt.Sprintf("Health: %d", s.Health))
-		if s.EliminatedCause != rules.NotEliminated {
-			o.WriteString(fmt.Sprintf(", Eliminated: %v, Turn: %d", s.EliminatedCause, s.EliminatedOnTurn))
-		}
-		o.WriteString("\n")
 	}
+
+	rows := make([]string, 0, boardState.Height)
 	for y := boardState.Height - 1; y >= 0; y-- {
+		var row strings.Builder
 		if gameState.UseColor {
-			o.WriteString(TERM_BG_WHITE)
+			row.WriteString(TERM_BG_WHITE)
 		}
 		for x := int(0); x < boardState.Width; x++ {
-			o.WriteString(board[x][y])
+			row.WriteString(board[x][y])
 		}
 		if gameState.UseColor {
-			o.WriteString(TERM_RESET)
+			row.WriteString(TERM_RESET)
 		}
-		o.WriteString("\n")
+		rows = append(rows, row.String())
 	}
-	fmt.Println(o.String())
+	return rows
 }
 
 func (gameState *GameState) buildFrameEvent(boardState *rules.BoardState) board.GameEvent {
@@ -823,6 +1210,14 @@ func convertRulesSnakes(snakes []rules.Snake, snakeStates map[string]SnakeState)
 	return a
 }
 
+// Returns the URL to open in the browser to watch a game served by a board server.
+func browserBoardURL(boardURL string, serverURL string, gameID string, offline bool) string {
+	if offline {
+		return board.ViewerURL(serverURL, gameID)
+	}
+	return fmt.Sprintf(boardURL+"?engine=%s&game=%s&autoplay=true", serverURL, gameID)
+}
+
 func convertStateToBoard(boardState *rules.BoardState, snakeStates map[string]SnakeState) client.Board {
 	return client.Board{
 		Height:  boardState.Height,
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/BattlesnakeOfficial/rules"
 	"github.com/BattlesnakeOfficial/rules/board"
 	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/BattlesnakeOfficial/rules/maps"
 	"github.com/BattlesnakeOfficial/rules/test"
 	"github.com/stretchr/testify/require"
 )
@@ -373,6 +375,19 @@ func TestBuildFrameEvent(t *testing.T) {
 	}
 }
 
+func TestBuildFrameEventEntities(t *testing.T) {
+	settings := rules.NewSettingsWithParams("arcade_maze.ghosts", "true")
+	boardState, err := maps.SetupBoard("arcade_maze", settings, 19, 21, nil)
+	require.NoError(t, err)
+
+	// entities are drawn as the hazards they cover, not as snakes
+	gameState := GameState{snakeStates: map[string]SnakeState{}}
+	frame := gameState.buildFrameEvent(boardState).Data.(board.GameFrame)
+	require.Empty(t, frame.Snakes)
+	require.Contains(t, frame.Hazards, rules.Point{X: 1, Y: 1})
+	require.Contains(t, frame.Hazards, rules.Point{X: 17, Y: 1})
+}
+
 func TestGetMoveForSnake(t *testing.T) {
 	s1 := rules.Snake{ID: "one", Body: []rules.Point{{X: 3, Y: 3}}}
 	s2 := rules.Snake{ID: "two", Body: []rules.Point{{X: 4, Y: 3}}}
@@ -647,3 +662,119 @@ func (client stubHTTPClient) Get(url string) (*http.Response, time.Duration, err
 func (client stubHTTPClient) Post(url string, contentType string, body io.Reader) (*http.Response, time.Duration, error) {
 	return client.request(url)
 }
+
+func TestPlayWithMapFile(t *testing.T) {
+	gameState := buildDefaultGameState()
+	gameState.Width, gameState.Height = 7, 7
+	gameState.MapFile = filepath.Join("..", "..", "maps", "testdata", "corners.yaml")
+	require.NoError(t, gameState.Initialize())
+	require.Equal(t, "corners", gameState.MapName)
+	require.Equal(t, "corners", gameState.gameMap.ID())
+
+	gameState.httpClient = stubHTTPClient{nil, http.StatusOK, func(_ string) string { return `{}` }, time.Millisecond}
+	gameState.snakeStates = map[string]SnakeState{
+		"one": {ID: "one", URL: "http://example.com"},
+		"two": {ID: "two", URL: "http://example.com"},
+	}
+	_, boardState, err := gameState.initializeBoardFromArgs()
+	require.NoError(t, err)
+	startPositions := []rules.Point{{X: 1, Y: 1}, {X: 5, Y: 1}, {X: 1, Y: 5}, {X: 5, Y: 5}}
+	for _, snake := range boardState.Snakes {
+		require.Contains(t, startPositions, snake.Body[0])
+	}
+	require.Equal(t, []rules.Point{{X: 3, Y: 3}}, boardState.Hazards)
+
+	gameState = buildDefaultGameState()
+	gameState.MapFile = filepath.Join("testdata", "missing.json")
+	require.Error(t, gameState.Initialize())
+}
+
+func TestPlayWithCompositeMap(t *testing.T) {
+	gameState := buildDefaultGameState()
+	gameState.MapName = "royale+healing_pools"
+	require.NoError(t, gameState.Initialize())
+	require.Equal(t, "royale+healing_pools", gameState.gameMap.ID())
+
+	gameState.snakeStates = map[string]SnakeState{
+		"one": {ID: "one", URL: "http://example.com"},
+		"two": {ID: "two", URL: "http://example.com"},
+	}
+	_, boardState, err := gameState.initializeBoardFromArgs()
+	require.NoError(t, err)
+	require.Len(t, boardState.Hazards, 2)
+
+	gameState = buildDefaultGameState()
+	gameState.MapName = "royale+missing"
+	require.Error(t, gameState.Initialize())
+}
+
+func TestPlayMapParams(t *testing.T) {
+	gameState := buildDefaultGameState()
+	gameState.MapName = "sinkholes"
+	gameState.Params = []string{"sinkholes.maxRings=2"}
+	require.NoError(t, gameState.Initialize())
+	require.Equal(t, map[string]string{"sinkholes.maxRings": "2"}, gameState.mapParams)
+	require.Equal(t, 2, gameState.ruleset.Settings().Int("sinkholes.maxRings", 0))
+
+	gameState = buildDefaultGameState()
+	gameState.MapName = "sinkholes"
+	gameState.Params = []string{"sinkholes.maxRings"}
+	require.EqualError(t, gameState.Initialize(), `Invalid map parameter "sinkholes.maxRings", expected key=value`)
+
+	gameState = buildDefaultGameState()
+	gameState.MapName = "sinkholes"
+	gameState.Params = []string{"sinkholes.maxRings=lots"}
+	require.EqualError(t, gameState.Initialize(), `Invalid map parameter: parameter sinkholes.maxRings must be an integer, got "lots"`)
+
+	gameState = buildDefaultGameState()
+	gameState.Params = []string{"sinkholes.maxRings=2"}
+	require.EqualError(t, gameState.Initialize(), "Invalid map parameter: map Standard doesn't have a parameter named sinkholes.maxRings")
+}
+
+func TestPlayMapDefaultSettings(t *testing.T) {
+	// arcade_maze recommends deadly hazards
+	gameState := buildDefaultGameState()
+	gameState.MapName = "arcade_maze"
+	require.NoError(t, gameState.Initialize())
+	require.Equal(t, 100, gameState.ruleset.Settings().Int(rules.ParamHazardDamagePerTurn, 0))
+
+	// settings given on the command line are used instead
+	gameState = buildDefaultGameState()
+	gameState.MapName = "arcade_maze"
+	gameState.markSettingSet(rules.ParamHazardDamagePerTurn)
+	require.NoError(t, gameState.Initialize())
+	require.Equal(t, 14, gameState.ruleset.Settings().Int(rules.ParamHazardDamagePerTurn, 0))
+}
+
+func TestPlayMapVersion(t *testing.T) {
+	// an older version of the standard map that only places a single food
+	oldStandard := standardMapV1{}
+	stub := maps.StubMap{Id: "standard", Food: []rules.Point{{X: 1, Y: 1}}}
+	maps.TestMap("standard@1", stubMapVersion{stub, oldStandard.Meta()}, func() {
+		gameState := buildDefaultGameState()
+		gameState.MapName = "standard@1"
+		require.NoError(t, gameState.Initialize())
+		require.Equal(t, 1, gameState.gameMap.Meta().Version)
+
+		// the board is set up with the version that was chosen
+		gameState.snakeStates = map[string]SnakeState{
+			"one": {ID: "one", URL: "http://example.com"},
+		}
+		_, boardState, err := gameState.initializeBoardFromArgs()
+		require.NoError(t, err)
+		require.Equal(t, []rules.Point{{X: 1, Y: 1}}, boardState.Food)
+
+		// and snakes are told which version is being played
+		require.Equal(t, "standard@1", gameState.createClientGame().Map)
+	})
+}
+
+// A StubMap with the metadata of another map.
+type stubMapVersion struct {
+	maps.StubMap
+	meta maps.Metadata
+}
+
+func (m stubMapVersion) Meta() maps.Metadata {
+	return m.meta
+}
@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/spf13/cobra"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+type mapPreview struct {
+	Width    int
+	Height   int
+	Players  int
+	Seed     int64
+	Turn     int
+	UseColor bool
+}
+
+func NewMapPreviewCommand() *cobra.Command {
+	preview := &mapPreview{}
+
+	var previewCmd = &cobra.Command{
+		Use:   "preview [flags] map_name|map_file",
+		Short: "Draw the board a map sets up",
+		Long: "Set up a board with a map and draw it in the terminal, using stand-in snakes for the players.\n" +
+			"Use --turn to also draw the board after the map has updated it for a number of turns, for maps that change as the game goes on. The stand-in snakes don't move.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			gameMap, err := loadMapArg(args[0])
+			if err != nil {
+				log.ERROR.Fatalf("Failed to load game map %v: %v", args[0], err)
+			}
+			if err := preview.Run(gameMap); err != nil {
+				log.ERROR.Fatalf("Failed to preview game map %v: %v", args[0], err)
+			}
+		},
+	}
+
+	previewCmd.Flags().IntVarP(&preview.Width, "width", "W", 0, "Width of Board (default: the map's first board size, or 11)")
+	previewCmd.Flags().IntVarP(&preview.Height, "height", "H", 0, "Height of Board (default: the map's first board size, or 11)")
+	previewCmd.Flags().IntVarP(&preview.Players, "players", "p", 0, "Number of players (default: 2, or the nearest number the map supports)")
+	previewCmd.Flags().Int64VarP(&preview.Seed, "seed", "r", time.Now().UTC().UnixNano(), "Random Seed")
+	previewCmd.Flags().IntVarP(&preview.Turn, "turn", "n", 0, "Also draw the board after this many turns")
+	previewCmd.Flags().BoolVarP(&preview.UseColor, "color", "c", false, "Use color to draw the map")
+
+	previewCmd.Flags().SortFlags = false
+
+	return previewCmd
+}
+
+// Fill in the board size and number of players from the map's metadata, if
+// they weren't given, and check that the map supports them.
+func (preview *mapPreview) initialize(meta maps.Metadata) error {
+	if preview.Width == 0 && preview.Height == 0 {
+		preview.Width, preview.Height = rules.BoardSizeMedium, rules.BoardSizeMedium
+		if !meta.BoardSizes.IsUnlimited() {
+			preview.Width, preview.Height = meta.BoardSizes[0].Width, meta.BoardSizes[0].Height
+		}
+	} else if preview.Width == 0 {
+		preview.Width = preview.Height
+	} else if preview.Height == 0 {
+		preview.Height = preview.Width
+	}
+	if !meta.BoardSizes.IsAllowable(preview.Width, preview.Height) {
+		return fmt.Errorf("the map doesn't support a %dx%d board", preview.Width, preview.Height)
+	}
+
+	if preview.Players == 0 {
+		preview.Players = 2
+		if preview.Players > meta.MaxPlayers {
+			preview.Players = meta.MaxPlayers
+		}
+		if preview.Players < meta.MinPlayers {
+			preview.Players = meta.MinPlayers
+		}
+	}
+	if preview.Players < meta.MinPlayers || preview.Players > meta.MaxPlayers {
+		return fmt.Errorf("the map supports %d-%d players", meta.MinPlayers, meta.MaxPlayers)
+	}
+	if preview.Turn < 0 {
+		return fmt.Errorf("turn must not be negative")
+	}
+	return nil
+}
+
+// Set up the board, and update it for the requested number of turns. Returns
+// the board on turn 0, and on the requested turn if it's after turn 0.
+func (preview *mapPreview) boardStates(gameMap maps.GameMap) ([]*rules.BoardState, error) {
+	if err := preview.initialize(gameMap.Meta()); err != nil {
+		return nil, err
+	}
+
+	settings := mapSettings(preview.Seed)
+	boardState, err := setupMapBoard(gameMap, preview.Width, preview.Height, standInSnakeIDs(preview.Players), settings)
+	if err != nil {
+		return nil, err
+	}
+	boardStates := []*rules.BoardState{boardState}
+	if preview.Turn == 0 {
+		return boardStates, nil
+	}
+
+	for boardState.Turn < preview.Turn {
+		next, err := updateMapBoard(gameMap, boardState, settings)
+		if err != nil {
+			return nil, fmt.Errorf("turn %d: %w", boardState.Turn, err)
+		}
+		boardState = next
+	}
+	return append(boardStates, boardState), nil
+}
+
+func (preview *mapPreview) Run(gameMap maps.GameMap) error {
+	boardStates, err := preview.boardStates(gameMap)
+	if err != nil {
+		return err
+	}
+
+	gameState := &GameState{
+		UseColor:    preview.UseColor,
+		snakeStates: standInSnakeStates(boardStates[0]),
+	}
+	fmt.Printf("%s: %dx%d, %d players, seed %d\n", gameMap.Meta().Name, preview.Width, preview.Height, preview.Players, preview.Seed)
+	for _, boardState := range boardStates {
+		gameState.printMap(boardState)
+	}
+	return nil
+}
+
+// Snake states for drawing the stand-in snakes, in the default snake color.
+func standInSnakeStates(boardState *rules.BoardState) map[string]SnakeState {
+	bodyChars := []rune{'■', '⌀', '●', '☻', '◘', '☺', '□', '⍟'}
+	snakeStates := map[string]SnakeState{}
+	for i, snake := range boardState.Snakes {
+		snakeStates[snake.ID] = SnakeState{
+			Name:      snake.ID,
+			ID:        snake.ID,
+			LastMove:  rules.MoveUp,
+			Character: bodyChars[i%8],
+		}
+	}
+	return snakeStates
+}
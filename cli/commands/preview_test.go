@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapPreviewDefaults(t *testing.T) {
+	preview := &mapPreview{}
+	require.NoError(t, preview.initialize(maps.StandardMap{}.Meta()))
+	require.Equal(t, 7, preview.Width)
+	require.Equal(t, 7, preview.Height)
+	require.Equal(t, 2, preview.Players)
+
+	preview = &mapPreview{Width: 9}
+	require.NoError(t, preview.initialize(maps.Metadata{MinPlayers: 4, MaxPlayers: 8, BoardSizes: maps.AnySize()}))
+	require.Equal(t, 9, preview.Height)
+	require.Equal(t, 4, preview.Players)
+
+	preview = &mapPreview{}
+	require.NoError(t, preview.initialize(maps.Metadata{MinPlayers: 1, MaxPlayers: 1, BoardSizes: maps.AnySize()}))
+	require.Equal(t, rules.BoardSizeMedium, preview.Width)
+	require.Equal(t, 1, preview.Players)
+
+	require.Error(t, (&mapPreview{Width: 8}).initialize(maps.StandardMap{}.Meta()))
+	require.Error(t, (&mapPreview{Players: 17}).initialize(maps.StandardMap{}.Meta()))
+	require.Error(t, (&mapPreview{Turn: -1}).initialize(maps.StandardMap{}.Meta()))
+}
+
+func TestMapPreviewBoardStates(t *testing.T) {
+	gameMap, err := loadMapArg(filepath.Join("..", "..", "maps", "testdata", "corners.json"))
+	require.NoError(t, err)
+
+	preview := &mapPreview{Players: 4, Seed: 1}
+	boardStates, err := preview.boardStates(gameMap)
+	require.NoError(t, err)
+	require.Len(t, boardStates, 1)
+	require.Equal(t, 0, boardStates[0].Turn)
+	require.Len(t, boardStates[0].Snakes, 4)
+	require.Equal(t, []rules.Point{{X: 3, Y: 3}}, boardStates[0].Hazards)
+
+	preview = &mapPreview{Players: 2, Seed: 1, Turn: 10}
+	boardStates, err = preview.boardStates(gameMap)
+	require.NoError(t, err)
+	require.Len(t, boardStates, 2)
+	require.Equal(t, 10, boardStates[1].Turn)
+	require.Equal(t, boardStates[0].Snakes[0].Body, boardStates[1].Snakes[0].Body)
+
+	// hz_spiral grows its hazards as the game goes on
+	gameMap, err = loadMapArg("hz_spiral")
+	require.NoError(t, err)
+	preview = &mapPreview{Seed: 3, Turn: 30}
+	boardStates, err = preview.boardStates(gameMap)
+	require.NoError(t, err)
+	require.Empty(t, boardStates[0].Hazards)
+	require.NotEmpty(t, boardStates[1].Hazards)
+
+	require.NoError(t, preview.Run(gameMap))
+}
@@ -0,0 +1,240 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+// A request sent to a snake and the response it gave, as written by the
+// record command, one per line.
+type recordedExchange struct {
+	Time       time.Time       `json:"time"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	Query      string          `json:"query,omitempty"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	StatusCode int             `json:"statusCode"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	Latency    int64           `json:"latency"` // milliseconds
+	Error      string          `json:"error,omitempty"`
+}
+
+type RecordState struct {
+	// Options
+	URL        string
+	Listen     string
+	OutputPath string
+	Timeout    int
+
+	// Internal state
+	target     *url.URL
+	httpClient TimedHttpClient
+	output     io.Writer
+	mutex      sync.Mutex
+}
+
+func NewRecordCommand() *cobra.Command {
+	recordState := &RecordState{}
+
+	var recordCmd = &cobra.Command{
+		Use:   "record",
+		Short: "Record the requests sent to a Battlesnake and its responses.",
+		Long: "Run a proxy in front of a Battlesnake, which passes on every request and records it along with the snake's response.\n" +
+			"Play games against the proxy's address instead of the snake's, then use diff-snake to replay the move requests against another version of the snake.",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := recordState.Run(); err != nil {
+				log.ERROR.Fatalf("Error recording snake: %v", err)
+			}
+		},
+	}
+
+	recordCmd.Flags().StringVarP(&recordState.URL, "url", "u", "", "URL of the Battlesnake to record")
+	recordCmd.Flags().StringVarP(&recordState.Listen, "listen", "l", "127.0.0.1:8080", "Address for the proxy to listen on")
+	recordCmd.Flags().StringVarP(&recordState.OutputPath, "output", "o", "", "File path to record requests and responses to. Existing files will be appended to")
+	recordCmd.Flags().IntVarP(&recordState.Timeout, "timeout", "t", 5000, "Timeout in milliseconds for requests passed on to the snake")
+	_ = recordCmd.MarkFlagRequired("url")
+	_ = recordCmd.MarkFlagRequired("output")
+
+	recordCmd.Flags().SortFlags = false
+
+	return recordCmd
+}
+
+// Run the proxy until interrupted.
+func (recordState *RecordState) Run() error {
+	if err := recordState.initialize(); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(recordState.OutputPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to open output file: %w", err)
+	}
+	defer f.Close()
+	recordState.output = f
+
+	server := &http.Server{Addr: recordState.Listen, Handler: recordState}
+	interrupted, stopInterrupts := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopInterrupts()
+	go func() {
+		<-interrupted.Done()
+		_ = server.Shutdown(context.Background())
+	}()
+
+	log.INFO.Printf("Recording %s at http://%s, press Ctrl-C to stop", recordState.target, recordState.Listen)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (recordState *RecordState) initialize() error {
+	target, err := url.ParseRequestURI(recordState.URL)
+	if err != nil {
+		return fmt.Errorf("URL %v is not valid: %w", recordState.URL, err)
+	}
+	recordState.target = target
+
+	if recordState.Timeout <= 0 {
+		recordState.Timeout = 5000
+	}
+	if recordState.httpClient == nil {
+		recordState.httpClient = timedHTTPClient{
+			&http.Client{
+				Timeout: time.Duration(recordState.Timeout) * time.Millisecond,
+			},
+		}
+	}
+	return nil
+}
+
+// Pass a request on to the snake, send its response back and record both.
+func (recordState *RecordState) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	exchange := recordedExchange{
+		Time:   time.Now().UTC(),
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	exchange.Request = recordedBody(requestBody)
+
+	u := *recordState.target
+	u.Path = path.Join(u.Path, r.URL.Path)
+	// Snakes can rely on query parameters, both from the target URL and the request
+	if u.RawQuery == "" {
+		u.RawQuery = r.URL.RawQuery
+	} else if r.URL.RawQuery != "" {
+		u.RawQuery += "&" + r.URL.RawQuery
+	}
+	var res *http.Response
+	var latency time.Duration
+	if r.Method == http.MethodPost {
+		res, latency, err = recordState.httpClient.Post(u.String(), r.Header.Get("Content-Type"), bytes.NewBuffer(requestBody))
+	} else {
+		res, latency, err = recordState.httpClient.Get(u.String())
+	}
+	exchange.Latency = latency.Milliseconds()
+
+	if err != nil {
+		log.WARN.Printf("Request to %v failed: %v", u.String(), err)
+		exchange.Error = err.Error()
+		recordState.record(exchange)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	var responseBody []byte
+	if res.Body != nil {
+		defer res.Body.Close()
+		responseBody, err = ioutil.ReadAll(res.Body)
+		if err != nil {
+			exchange.Error = err.Error()
+		}
+	}
+	exchange.StatusCode = res.StatusCode
+	exchange.Response = recordedBody(responseBody)
+	recordState.record(exchange)
+
+	if contentType := res.Header.Get("Content-Type"); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.WriteHeader(res.StatusCode)
+	_, _ = w.Write(responseBody)
+}
+
+func (recordState *RecordState) record(exchange recordedExchange) {
+	line, err := json.Marshal(exchange)
+	if err != nil {
+		log.WARN.Printf("Unable to record %s %s: %v", exchange.Method, exchange.Path, err)
+		return
+	}
+
+	recordState.mutex.Lock()
+	defer recordState.mutex.Unlock()
+
+	if _, err := recordState.output.Write(append(line, '\n')); err != nil {
+		log.WARN.Printf("Unable to record %s %s: %v", exchange.Method, exchange.Path, err)
+	}
+}
+
+// Keep JSON bodies as they are, so that the recording is easy to read, and
+// record anything else as a string.
+func recordedBody(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if json.Valid(body) {
+		return json.RawMessage(body)
+	}
+	encoded, _ := json.Marshal(string(body))
+	return json.RawMessage(encoded)
+}
+
+// Read the exchanges written by the record command.
+func readRecording(path string) ([]recordedExchange, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var exchanges []recordedExchange
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var exchange recordedExchange
+		if err := json.Unmarshal(scanner.Bytes(), &exchange); err != nil {
+			return nil, fmt.Errorf("Invalid recording on line %d: %w", line, err)
+		}
+		exchanges = append(exchanges, exchange)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return exchanges, nil
+}
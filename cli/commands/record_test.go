@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/BattlesnakeOfficial/rules/scenario"
+	"github.com/stretchr/testify/require"
+)
+
+// A snake that only moves in games it's been sent a start request for.
+func newRecordedSnakeServer(t *testing.T) *httptest.Server {
+	var mutex sync.Mutex
+	games := map[string]bool{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request client.SnakeRequest
+		if r.Method == http.MethodPost {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch r.URL.Path {
+		case "/snake":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"apiversion": "1"}`))
+		case "/snake/start":
+			games[request.Game.ID] = true
+			_, _ = w.Write([]byte("ok"))
+		case "/snake/move":
+			if !games[request.Game.ID] {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			move := "up"
+			if request.Turn%2 == 1 {
+				move = "left"
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"move": "` + move + `"}`))
+		case "/snake/end":
+			delete(games, request.Game.ID)
+			_, _ = w.Write([]byte("ok"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestRecordAndDiffSnake(t *testing.T) {
+	snake := newRecordedSnakeServer(t)
+	defer snake.Close()
+
+	recordingPath := filepath.Join(t.TempDir(), "recording.jsonl")
+	f, err := os.Create(recordingPath)
+	require.NoError(t, err)
+	recordState := &RecordState{URL: snake.URL + "/snake", output: f}
+	require.NoError(t, recordState.initialize())
+	proxy := httptest.NewServer(recordState)
+	defer proxy.Close()
+
+	res, err := http.Get(proxy.URL)
+	require.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	require.JSONEq(t, `{"apiversion": "1"}`, string(body))
+	require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+	res, err = http.Post(proxy.URL+"/start", "application/json", strings.NewReader(`{"game": {"id": "game"}}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	for turn := 0; turn < 4; turn++ {
+		request := client.SnakeRequest{Game: client.Game{ID: "game"}, Turn: turn, You: client.Snake{Name: "recorded"}}
+		requestBody, _ := json.Marshal(request)
+		res, err = http.Post(proxy.URL+"/move", "application/json", bytes.NewBuffer(requestBody))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	}
+	res, err = http.Post(proxy.URL+"/end", "application/json", strings.NewReader(`{"game": {"id": "game"}}`))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res, err = http.Get(proxy.URL + "/missing")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+	require.NoError(t, f.Close())
+
+	exchanges, err := readRecording(recordingPath)
+	require.NoError(t, err)
+	require.Len(t, exchanges, 8)
+	require.Equal(t, "/", exchanges[0].Path)
+	require.Equal(t, "/start", exchanges[1].Path)
+	require.Equal(t, "/move", exchanges[2].Path)
+	require.JSONEq(t, `{"move": "left"}`, string(exchanges[3].Response))
+	require.Equal(t, json.RawMessage(`"ok"`), exchanges[6].Response)
+	require.Equal(t, http.StatusNotFound, exchanges[7].StatusCode)
+
+	// the same snake makes the same moves, once it's been sent the start of the game
+	diffSnakeState := &DiffSnakeState{URL: snake.URL + "/snake"}
+	diffs := diffSnakeState.Run(exchanges)
+	require.Len(t, diffs, 4)
+	require.True(t, printSnakeDiffs(diffs))
+
+	// and it's sent the end of the game afterwards
+	diffs = diffSnakeState.Run(exchanges)
+	require.True(t, printSnakeDiffs(diffs))
+	diffs = diffSnakeState.Run(exchanges[2:])
+	require.Error(t, diffs[0].Error)
+
+	// a new version that always moves up
+	diffSnakeState = &DiffSnakeState{agent: scenario.AgentFunc(func(request client.SnakeRequest) (client.MoveResponse, error) {
+		if request.Turn == 3 {
+			return client.MoveResponse{}, errors.New("timed out")
+		}
+		return client.MoveResponse{Move: "up"}, nil
+	})}
+	diffs = diffSnakeState.Run(exchanges)
+	require.False(t, printSnakeDiffs(diffs))
+	require.True(t, diffs[0].Matches())
+	require.Equal(t, snakeDiff{GameID: "game", Turn: 1, SnakeName: "recorded", RecordedMove: "left", Move: "up"}, diffs[1])
+	require.EqualError(t, diffs[3].Error, "timed out")
+}
+
+func TestRecordQuery(t *testing.T) {
+	var queries []string
+	snake := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		_, _ = w.Write([]byte(`{"move": "up"}`))
+	}))
+	defer snake.Close()
+
+	var output bytes.Buffer
+	recordState := &RecordState{URL: snake.URL + "?key=secret", output: &output}
+	require.NoError(t, recordState.initialize())
+
+	// query parameters are forwarded to the snake along with the ones in its URL, and recorded
+	w := httptest.NewRecorder()
+	recordState.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/move?version=2", strings.NewReader(`{"turn": 1}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	w = httptest.NewRecorder()
+	recordState.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, []string{"key=secret&version=2", "key=secret"}, queries)
+
+	var exchange recordedExchange
+	require.NoError(t, json.NewDecoder(&output).Decode(&exchange))
+	require.Equal(t, "/move", exchange.Path)
+	require.Equal(t, "version=2", exchange.Query)
+}
+
+func TestRecordUnreachableSnake(t *testing.T) {
+	var output bytes.Buffer
+	recordState := &RecordState{URL: "http://127.0.0.1:1", Timeout: 100, output: &output}
+	require.NoError(t, recordState.initialize())
+
+	w := httptest.NewRecorder()
+	recordState.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/move", strings.NewReader(`{"turn": 1}`)))
+	require.Equal(t, http.StatusBadGateway, w.Code)
+
+	var exchange recordedExchange
+	require.NoError(t, json.Unmarshal(output.Bytes(), &exchange))
+	require.JSONEq(t, `{"turn": 1}`, string(exchange.Request))
+	require.NotEmpty(t, exchange.Error)
+}
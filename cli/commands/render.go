@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"fmt"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/render"
+	"github.com/spf13/cobra"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+type RenderState struct {
+	OutputPath string
+	Turn       int
+	CellSize   int
+	FrameDelay int
+
+	// SVG overlays
+	ShowCoordinates bool
+	ShowMoves       bool
+}
+
+func NewRenderCommand() *cobra.Command {
+	renderState := &RenderState{}
+
+	var renderCmd = &cobra.Command{
+		Use:   "render <file>",
+		Short: "Draw a recorded game as a PNG, an SVG or an animated GIF.",
+		Long: "Draw a game recorded with the --output option of the play command as an image.\n" +
+			"If the output file ends in .png or .svg, a single turn is drawn. If it ends in .gif, every turn is drawn as an animation.\n" +
+			"SVGs can also show the coordinates of each cell and the move each snake chose on the turn.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			export, err := readGameExportFile(args[0])
+			if err != nil {
+				log.ERROR.Fatalf("Error reading game: %v", err)
+			}
+			if err := renderState.Run(export); err != nil {
+				log.ERROR.Fatalf("Error rendering game: %v", err)
+			}
+		},
+	}
+
+	renderCmd.Flags().StringVarP(&renderState.OutputPath, "output", "o", "", "File to write the image to, ending in .png, .svg or .gif")
+	renderCmd.Flags().IntVar(&renderState.Turn, "turn", -1, "Turn to draw in a PNG or SVG (default: the last turn)")
+	renderCmd.Flags().IntVar(&renderState.CellSize, "cell-size", render.DefaultCellSize, "Size of each board cell in pixels")
+	renderCmd.Flags().IntVarP(&renderState.FrameDelay, "delay", "d", 100, "Delay between GIF frames in milliseconds")
+	renderCmd.Flags().BoolVar(&renderState.ShowCoordinates, "coordinates", false, "Label the rows and columns of the board in an SVG")
+	renderCmd.Flags().BoolVar(&renderState.ShowMoves, "moves", false, "Draw an arrow for the move each snake chose in an SVG")
+	_ = renderCmd.MarkFlagRequired("output")
+
+	renderCmd.Flags().SortFlags = false
+
+	return renderCmd
+}
+
+func (renderState *RenderState) Run(export *gameExport) error {
+	boardStates := boardStatesFromExport(export)
+	options := render.Options{
+		CellSize:    renderState.CellSize,
+		SnakeColors: map[string]string{},
+	}
+	for id, snakeState := range snakeStatesFromExport(export) {
+		options.SnakeColors[id] = snakeState.Color
+	}
+
+	switch strings.ToLower(filepath.Ext(renderState.OutputPath)) {
+	case ".png":
+		turnIndex, err := renderState.turnIndex(boardStates)
+		if err != nil {
+			return err
+		}
+		return writeImageFile(renderState.OutputPath, func(f *os.File) error {
+			return png.Encode(f, render.Image(boardStates[turnIndex], options))
+		})
+	case ".svg":
+		turnIndex, err := renderState.turnIndex(boardStates)
+		if err != nil {
+			return err
+		}
+		overlays := render.Overlays{Coordinates: renderState.ShowCoordinates}
+		if renderState.ShowMoves {
+			overlays.Moves = map[string]string{}
+			for _, move := range export.Turns[turnIndex].Moves {
+				overlays.Moves[move.ID] = move.Move
+			}
+		}
+		return writeImageFile(renderState.OutputPath, func(f *os.File) error {
+			return render.SVG(f, boardStates[turnIndex], options, overlays)
+		})
+	case ".gif":
+		// GIF delays are in 100ths of a second
+		animation := render.GIF(boardStates, options, renderState.FrameDelay/10)
+		return writeImageFile(renderState.OutputPath, func(f *os.File) error {
+			return gif.EncodeAll(f, animation)
+		})
+	}
+	return fmt.Errorf("output file %s should end in .png, .svg or .gif", renderState.OutputPath)
+}
+
+// Find the index of the turn to draw.
+func (renderState *RenderState) turnIndex(boardStates []*rules.BoardState) (int, error) {
+	if renderState.Turn < 0 {
+		return len(boardStates) - 1, nil
+	}
+	for i, boardState := range boardStates {
+		if boardState.Turn == renderState.Turn {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("turn %d isn't in the recording, which ends on turn %d", renderState.Turn, boardStates[len(boardStates)-1].Turn)
+}
+
+func writeImageFile(path string, encode func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := encode(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	export, err := readGameExport(strings.NewReader(replayExport))
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	renderState := &RenderState{OutputPath: filepath.Join(dir, "game.png"), Turn: 1, CellSize: 10, FrameDelay: 100}
+	require.NoError(t, renderState.Run(export))
+	f, err := os.Open(renderState.OutputPath)
+	require.NoError(t, err)
+	img, err := png.Decode(f)
+	f.Close()
+	require.NoError(t, err)
+	require.Equal(t, 70, img.Bounds().Dx())
+	require.Equal(t, 70, img.Bounds().Dy())
+
+	renderState = &RenderState{OutputPath: filepath.Join(dir, "game.gif"), Turn: -1, CellSize: 10, FrameDelay: 250}
+	require.NoError(t, renderState.Run(export))
+	f, err = os.Open(renderState.OutputPath)
+	require.NoError(t, err)
+	animation, err := gif.DecodeAll(f)
+	f.Close()
+	require.NoError(t, err)
+	require.Len(t, animation.Image, 3)
+	require.Equal(t, []int{25, 25, 25}, animation.Delay)
+
+	renderState = &RenderState{OutputPath: filepath.Join(dir, "game.svg"), Turn: 0, CellSize: 10, ShowCoordinates: true, ShowMoves: true}
+	require.NoError(t, renderState.Run(export))
+	data, err := os.ReadFile(renderState.OutputPath)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(data), `<svg xmlns="http://www.w3.org/2000/svg" width="80" height="80"`))
+	require.Contains(t, string(data), `fill="#123456"`)
+
+	renderState = &RenderState{OutputPath: filepath.Join(dir, "game.png"), Turn: 5}
+	require.EqualError(t, renderState.Run(export), "turn 5 isn't in the recording, which ends on turn 2")
+
+	renderState = &RenderState{OutputPath: filepath.Join(dir, "game.jpg"), Turn: -1}
+	require.Error(t, renderState.Run(export))
+}
@@ -0,0 +1,295 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/board"
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/pkg/browser"
+	"github.com/spf13/cobra"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+// Elimination cause used for snakes that disappear from a recorded game, since the export doesn't include the cause.
+const eliminatedByUnknown = "unknown"
+
+type ReplayState struct {
+	// Options
+	TurnDelay     int
+	UseColor      bool
+	UseTUI        bool
+	ViewInBrowser bool
+	BoardURL      string
+	Offline       bool
+
+	// Internal state
+	export      *gameExport
+	boardStates []*rules.BoardState
+	gameState   *GameState
+}
+
+func NewReplayCommand() *cobra.Command {
+	replayState := &ReplayState{}
+
+	var replayCmd = &cobra.Command{
+		Use:   "replay <file>",
+		Short: "Replay a game recorded with play --output.",
+		Long:  "Replay a game recorded with play --output, either in the terminal or in the browser using the Battlesnake game board.",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := replayState.Load(args[0]); err != nil {
+				log.ERROR.Fatalf("Error loading game: %v", err)
+			}
+			if err := replayState.Run(); err != nil {
+				log.ERROR.Fatalf("Error replaying game: %v", err)
+			}
+		},
+	}
+
+	replayCmd.Flags().IntVarP(&replayState.TurnDelay, "delay", "d", 100, "Turn Delay in Milliseconds")
+	replayCmd.Flags().BoolVarP(&replayState.UseColor, "color", "c", false, "Use color to draw the map")
+	replayCmd.Flags().BoolVar(&replayState.UseTUI, "tui", false, "Step through the game in a full-screen terminal UI")
+	replayCmd.Flags().BoolVar(&replayState.ViewInBrowser, "browser", false, "View the game in the browser using the Battlesnake game board")
+	replayCmd.Flags().StringVar(&replayState.BoardURL, "board-url", "https://board.battlesnake.com", "Base URL for the game board when using --browser")
+	replayCmd.Flags().BoolVar(&replayState.Offline, "offline", false, "Use the board viewer built into the CLI when using --browser, instead of the Battlesnake game board")
+
+	replayCmd.Flags().SortFlags = false
+
+	return replayCmd
+}
+
+// Read the recorded game and rebuild the board state for each turn.
+func (replayState *ReplayState) Load(path string) error {
+	export, err := readGameExportFile(path)
+	if err != nil {
+		return err
+	}
+	replayState.export = export
+	replayState.boardStates = boardStatesFromExport(export)
+	replayState.gameState = &GameState{
+		UseColor:    replayState.UseColor,
+		snakeStates: snakeStatesFromExport(export),
+	}
+	return nil
+}
+
+// Render every turn of the loaded game in the terminal or stream it to the browser.
+func (replayState *ReplayState) Run() error {
+	if replayState.ViewInBrowser {
+		return replayState.runInBrowser()
+	}
+	if replayState.UseTUI {
+		return replayState.runInTerminalUI()
+	}
+
+	for i, boardState := range replayState.boardStates {
+		replayState.updateSnakeStates(i)
+		replayState.gameState.printMap(boardState)
+
+		if replayState.TurnDelay > 0 && i < len(replayState.boardStates)-1 {
+			time.Sleep(time.Duration(replayState.TurnDelay) * time.Millisecond)
+		}
+	}
+	replayState.printResult()
+
+	return nil
+}
+
+func (replayState *ReplayState) runInBrowser() error {
+	boardGame := boardGameFromExport(replayState.export)
+	boardServer := board.NewBoardServer(boardGame)
+
+	serverURL, err := boardServer.Listen()
+	if err != nil {
+		return fmt.Errorf("Error starting HTTP server: %w", err)
+	}
+	defer boardServer.Shutdown()
+	log.INFO.Printf("Board server listening on %s", serverURL)
+
+	boardURL := browserBoardURL(replayState.BoardURL, serverURL, boardGame.ID, replayState.Offline)
+
+	log.INFO.Printf("Opening board URL: %s", boardURL)
+	if err := browser.OpenURL(boardURL); err != nil {
+		log.ERROR.Printf("Failed to open browser: %v", err)
+	}
+
+	for i, boardState := range replayState.boardStates {
+		replayState.updateSnakeStates(i)
+		boardServer.SendEvent(replayState.gameState.buildFrameEvent(boardState))
+
+		if replayState.TurnDelay > 0 && i < len(replayState.boardStates)-1 {
+			time.Sleep(time.Duration(replayState.TurnDelay) * time.Millisecond)
+		}
+	}
+	boardServer.SendEvent(board.GameEvent{
+		EventType: board.EVENT_TYPE_GAME_END,
+		Data:      boardGame,
+	})
+	replayState.printResult()
+
+	return nil
+}
+
+func (replayState *ReplayState) runInTerminalUI() error {
+	ui := newTerminalUI(replayState.UseColor, time.Duration(replayState.TurnDelay)*time.Millisecond)
+	for i, boardState := range replayState.boardStates {
+		replayState.updateSnakeStates(i)
+		ui.AddTurn(boardState, replayState.gameState.snakeStates)
+	}
+	ui.SetStatus(replayState.resultMessage())
+
+	if err := ui.Start(); err != nil {
+		return err
+	}
+	ui.Run()
+	ui.Close()
+	replayState.printResult()
+
+	return nil
+}
+
+// Copy what was recorded about each snake on a turn into the snake states, so
+// that it's rendered with the turn. The moves that led to a turn are recorded
+// with the turn before it.
+func (replayState *ReplayState) updateSnakeStates(turnIndex int) {
+	for _, snake := range replayState.export.Turns[turnIndex].Board.Snakes {
+		snakeState := replayState.gameState.snakeStates[snake.ID]
+		latencyMS, _ := strconv.Atoi(snake.Latency)
+		snakeState.Latency = time.Duration(latencyMS) * time.Millisecond
+		snakeState.Shout = snake.Shout
+		replayState.gameState.snakeStates[snake.ID] = snakeState
+	}
+	if turnIndex == 0 {
+		return
+	}
+	for _, move := range replayState.export.Turns[turnIndex-1].Moves {
+		snakeState := replayState.gameState.snakeStates[move.ID]
+		snakeState.LastMove = move.Move
+		snakeState.StatusCode = move.StatusCode
+		snakeState.Error = nil
+		if move.Error != "" {
+			snakeState.Error = errors.New(move.Error)
+		}
+		replayState.gameState.snakeStates[move.ID] = snakeState
+	}
+}
+
+func (replayState *ReplayState) printResult() {
+	log.INFO.Print(replayState.resultMessage())
+}
+
+func (replayState *ReplayState) resultMessage() string {
+	lastTurn := replayState.boardStates[len(replayState.boardStates)-1].Turn
+	result := replayState.export.Result
+	if result == nil {
+		return fmt.Sprintf("Recording ended after %v turns without a result.", lastTurn)
+	} else if result.IsDraw {
+		return fmt.Sprintf("Game completed after %v turns. It was a draw.", lastTurn)
+	} else if result.WinnerName != "" {
+		return fmt.Sprintf("Game completed after %v turns. %v was the winner.", lastTurn, result.WinnerName)
+	}
+	return fmt.Sprintf("Game completed after %v turns.", lastTurn)
+}
+
+// Build the local snake state for every snake that appears in a recorded game.
+func snakeStatesFromExport(export *gameExport) map[string]SnakeState {
+	bodyChars := []rune{'■', '⌀', '●', '☻', '◘', '☺', '□', '⍟'}
+	snakeStates := map[string]SnakeState{}
+	for _, turn := range export.Turns {
+		for _, snake := range turn.Board.Snakes {
+			if _, ok := snakeStates[snake.ID]; ok {
+				continue
+			}
+			snakeStates[snake.ID] = SnakeState{
+				Name:       snake.Name,
+				ID:         snake.ID,
+				LastMove:   rules.MoveUp,
+				Character:  bodyChars[len(snakeStates)%8],
+				Color:      snake.Customizations.Color,
+				Head:       snake.Customizations.Head,
+				Tail:       snake.Customizations.Tail,
+				StatusCode: http.StatusOK,
+			}
+		}
+	}
+	return snakeStates
+}
+
+// Convert each recorded turn into a BoardState. The export only contains
+// snakes that are still alive, so snakes that disappear from the board are
+// kept with their last known body and marked as eliminated.
+func boardStatesFromExport(export *gameExport) []*rules.BoardState {
+	boardStates := make([]*rules.BoardState, 0, len(export.Turns))
+	var lastSnakes []rules.Snake
+	for _, turn := range export.Turns {
+		boardState := boardStateFromTurn(turn)
+
+		present := map[string]bool{}
+		for _, snake := range boardState.Snakes {
+			present[snake.ID] = true
+		}
+		for _, snake := range lastSnakes {
+			if present[snake.ID] {
+				continue
+			}
+			if snake.EliminatedCause == rules.NotEliminated {
+				snake.EliminatedCause = eliminatedByUnknown
+				snake.EliminatedOnTurn = boardState.Turn
+			}
+			boardState.Snakes = append(boardState.Snakes, snake)
+		}
+
+		boardStates = append(boardStates, boardState)
+		lastSnakes = boardState.Clone().Snakes
+	}
+	return boardStates
+}
+
+// Convert the board in a snake request to a BoardState.
+func boardStateFromRequest(snakeRequest client.SnakeRequest) *rules.BoardState {
+	boardState := rules.NewBoardState(snakeRequest.Board.Width, snakeRequest.Board.Height).
+		WithTurn(snakeRequest.Turn).
+		WithFood(PointFromCoordArray(snakeRequest.Board.Food)).
+		WithHazards(PointFromCoordArray(snakeRequest.Board.Hazards))
+	for _, snake := range snakeRequest.Board.Snakes {
+		boardState.Snakes = append(boardState.Snakes, rules.Snake{
+			ID:     snake.ID,
+			Body:   PointFromCoordArray(snake.Body),
+			Health: snake.Health,
+		})
+	}
+	return boardState
+}
+
+// Convert an exported turn back into a board state, including the map's state
+// and point state.
+func boardStateFromTurn(turn exportedTurn) *rules.BoardState {
+	boardState := boardStateFromRequest(turn.SnakeRequest)
+	for key, value := range turn.GameState {
+		boardState.GameState[key] = value
+	}
+	boardState.PointState = importPointState(turn.PointState)
+	return boardState
+}
+
+func boardGameFromExport(export *gameExport) board.Game {
+	firstTurn := export.Turns[0]
+	return board.Game{
+		ID:     export.Game.ID,
+		Status: "complete",
+		Width:  firstTurn.Board.Width,
+		Height: firstTurn.Board.Height,
+		Ruleset: map[string]string{
+			rules.ParamGameType: export.Game.Ruleset.Name,
+		},
+		SnakeTimeout: export.Game.Timeout,
+		RulesetName:  export.Game.Ruleset.Name,
+		RulesStages:  []string{},
+		Map:          export.mapID(),
+	}
+}
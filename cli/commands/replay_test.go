@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/stretchr/testify/require"
+)
+
+const replayExport = `{"id":"GAME_ID","ruleset":{"name":"standard","version":"cli"},"map":"standard","timeout":500,"source":""}
+{"game":{"id":"GAME_ID"},"turn":0,"board":{"height":7,"width":7,"snakes":[{"id":"one","name":"ONE","latency":"0","health":100,"body":[{"x":1,"y":1},{"x":1,"y":1}],"customizations":{"color":"#123456"}},{"id":"two","name":"TWO","latency":"0","health":100,"body":[{"x":5,"y":5},{"x":5,"y":5}]}],"food":[{"x":3,"y":3}],"hazards":[]}}
+{"game":{"id":"GAME_ID"},"turn":1,"board":{"height":7,"width":7,"snakes":[{"id":"one","name":"ONE","latency":"42","health":99,"body":[{"x":1,"y":2},{"x":1,"y":1}]},{"id":"two","name":"TWO","latency":"12","health":99,"body":[{"x":5,"y":6},{"x":5,"y":5}]}],"food":[{"x":3,"y":3}],"hazards":[{"x":0,"y":0}]}}
+{"game":{"id":"GAME_ID"},"turn":2,"board":{"height":7,"width":7,"snakes":[{"id":"one","name":"ONE","latency":"40","health":98,"body":[{"x":1,"y":3},{"x":1,"y":2}]}],"food":[{"x":3,"y":3}],"hazards":[]}}
+{"winnerId":"one","winnerName":"ONE","isDraw":false}
+`
+
+func TestReadGameExport(t *testing.T) {
+	export, err := readGameExport(strings.NewReader(replayExport))
+	require.NoError(t, err)
+
+	require.Equal(t, "GAME_ID", export.Game.ID)
+	require.Equal(t, "standard", export.Game.Map)
+	require.Len(t, export.Turns, 3)
+	require.Equal(t, 2, export.Turns[2].Turn)
+	require.Equal(t, &result{WinnerID: "one", WinnerName: "ONE"}, export.Result)
+
+	_, err = readGameExport(strings.NewReader(""))
+	require.Error(t, err)
+
+	_, err = readGameExport(strings.NewReader(`{"id":"GAME_ID"}` + "\n" + `{"foo":"bar"}`))
+	require.EqualError(t, err, "line 2: unrecognised line")
+}
+
+func TestBoardStatesFromExport(t *testing.T) {
+	export, err := readGameExport(strings.NewReader(replayExport))
+	require.NoError(t, err)
+
+	snakeStates := snakeStatesFromExport(export)
+	require.Len(t, snakeStates, 2)
+	require.Equal(t, "ONE", snakeStates["one"].Name)
+	require.Equal(t, "#123456", snakeStates["one"].Color)
+
+	boardStates := boardStatesFromExport(export)
+	require.Len(t, boardStates, 3)
+
+	require.Equal(t, 1, boardStates[1].Turn)
+	require.Equal(t, []rules.Point{{X: 0, Y: 0}}, boardStates[1].Hazards)
+	require.Equal(t, rules.Snake{
+		ID:     "one",
+		Body:   []rules.Point{{X: 1, Y: 2}, {X: 1, Y: 1}},
+		Health: 99,
+	}, boardStates[1].Snakes[0])
+
+	// snake two disappeared on turn 2, so it should be kept as eliminated
+	require.Len(t, boardStates[2].Snakes, 2)
+	require.Equal(t, rules.Snake{
+		ID:               "two",
+		Body:             []rules.Point{{X: 5, Y: 6}, {X: 5, Y: 5}},
+		Health:           99,
+		EliminatedCause:  eliminatedByUnknown,
+		EliminatedOnTurn: 2,
+	}, boardStates[2].Snakes[1])
+}
+
+func TestBoardGameFromExport(t *testing.T) {
+	export, err := readGameExport(strings.NewReader(replayExport))
+	require.NoError(t, err)
+	require.Equal(t, "standard", boardGameFromExport(export).Map)
+
+	// replays of older map versions show the version that was played
+	export.ResolvedMap = "standard@1"
+	boardGame := boardGameFromExport(export)
+	require.Equal(t, "standard@1", boardGame.Map)
+	require.Equal(t, 7, boardGame.Width)
+}
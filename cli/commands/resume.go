@@ -0,0 +1,181 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/BattlesnakeOfficial/rules"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+// Load the board state to resume from, and override the game options with
+// the ones the game was recorded with so that following turns are comparable.
+func (gameState *GameState) loadResumeState() error {
+	data, err := os.ReadFile(gameState.ResumePath)
+	if err != nil {
+		return err
+	}
+
+	// A serialized BoardState is a single JSON object with capitalized keys,
+	// while a game export starts with the API game object.
+	var keys map[string]json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&keys); err != nil {
+		return err
+	}
+	if _, ok := keys["Snakes"]; ok {
+		return gameState.loadResumeBoardState(data)
+	}
+	return gameState.loadResumeExport(data)
+}
+
+// A board state serialized as JSON, with the same fields as rules.BoardState.
+// PointState is a list of entries in the same format as game exports, since
+// JSON objects can only have string keys.
+type serializedBoardState struct {
+	Turn       int
+	Height     int
+	Width      int
+	Food       []rules.Point
+	Snakes     []rules.Snake
+	Hazards    []rules.Point
+	GameState  map[string]string
+	PointState []exportedPointState
+}
+
+// Resume from a board state serialized as JSON. The ruleset, map, settings
+// and seed are taken from the command-line options.
+func (gameState *GameState) loadResumeBoardState(data []byte) error {
+	var serialized serializedBoardState
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return fmt.Errorf("invalid board state: %w", err)
+	}
+
+	// Missing fields are initialized the same way as NewBoardState
+	boardState := rules.NewBoardState(serialized.Width, serialized.Height).WithTurn(serialized.Turn)
+	if serialized.Food != nil {
+		boardState.Food = serialized.Food
+	}
+	if serialized.Snakes != nil {
+		boardState.Snakes = serialized.Snakes
+	}
+	if serialized.Hazards != nil {
+		boardState.Hazards = serialized.Hazards
+	}
+	if serialized.GameState != nil {
+		boardState.GameState = serialized.GameState
+	}
+	boardState.PointState = importPointState(serialized.PointState)
+
+	gameState.Width = boardState.Width
+	gameState.Height = boardState.Height
+	gameState.resumeBoardState = boardState
+
+	return gameState.assignResumedSnakeIDs(map[string]string{})
+}
+
+// Resume from a turn in a file written with --output.
+func (gameState *GameState) loadResumeExport(data []byte) error {
+	export, err := readGameExport(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	turnIndex := len(export.Turns) - 1
+	if gameState.ResumeTurn >= 0 {
+		turnIndex = -1
+		for i, turn := range export.Turns {
+			if turn.Turn == gameState.ResumeTurn {
+				turnIndex = i
+				break
+			}
+		}
+		if turnIndex < 0 {
+			return fmt.Errorf("turn %d was not recorded", gameState.ResumeTurn)
+		}
+	}
+	turn := export.Turns[turnIndex]
+
+	settings := rules.NewSettings(paramsFromRulesetSettings(export.Game.Ruleset.Settings))
+	gameState.GameType = export.Game.Ruleset.Name
+	gameState.MapName = export.mapID()
+	gameState.FoodSpawnChance = settings.Int(rules.ParamFoodSpawnChance, gameState.FoodSpawnChance)
+	gameState.MinimumFood = settings.Int(rules.ParamMinimumFood, gameState.MinimumFood)
+	gameState.HazardDamagePerTurn = settings.Int(rules.ParamHazardDamagePerTurn, gameState.HazardDamagePerTurn)
+	gameState.ShrinkEveryNTurns = settings.Int(rules.ParamShrinkEveryNTurns, gameState.ShrinkEveryNTurns)
+	// The recorded settings and map parameters are used instead of the map's recommended settings
+	for _, param := range settingFlags {
+		gameState.markSettingSet(param)
+	}
+	gameState.Params = nil
+	for key, value := range export.MapParams {
+		gameState.Params = append(gameState.Params, key+"="+value)
+	}
+	if export.Seed != 0 {
+		gameState.Seed = export.Seed
+	} else {
+		log.WARN.Printf("Recorded game doesn't include a seed, using seed %d instead", gameState.Seed)
+	}
+	gameState.Width = turn.Board.Width
+	gameState.Height = turn.Board.Height
+	gameState.resumeBoardState = boardStateFromTurn(turn)
+
+	names := map[string]string{}
+	for _, snake := range turn.Board.Snakes {
+		names[snake.ID] = snake.Name
+	}
+	return gameState.assignResumedSnakeIDs(names)
+}
+
+// Give each snake from the command-line the ID of a snake on the resumed
+// board. Snakes are matched by name where possible and otherwise by order.
+// Snakes without a name on the command-line keep their recorded name.
+func (gameState *GameState) assignResumedSnakeIDs(recordedNames map[string]string) error {
+	var aliveIDs []string
+	for _, snake := range gameState.resumeBoardState.Snakes {
+		if snake.EliminatedCause == rules.NotEliminated {
+			aliveIDs = append(aliveIDs, snake.ID)
+		}
+	}
+	if len(gameState.URLs) != len(aliveIDs) {
+		return fmt.Errorf("the resumed board has %d snakes, but %d URLs were provided", len(aliveIDs), len(gameState.URLs))
+	}
+
+	ids := make([]string, len(aliveIDs))
+	assigned := map[string]bool{}
+	for i := range ids {
+		if i >= len(gameState.Names) {
+			break
+		}
+		for _, id := range aliveIDs {
+			if !assigned[id] && recordedNames[id] == gameState.Names[i] {
+				ids[i] = id
+				assigned[id] = true
+				break
+			}
+		}
+	}
+	next := 0
+	for i := range ids {
+		if ids[i] != "" {
+			continue
+		}
+		for assigned[aliveIDs[next]] {
+			next++
+		}
+		ids[i] = aliveIDs[next]
+		assigned[ids[i]] = true
+	}
+
+	for i := len(gameState.Names); i < len(ids); i++ {
+		if name, ok := recordedNames[ids[i]]; ok {
+			gameState.Names = append(gameState.Names, name)
+		} else {
+			break
+		}
+	}
+
+	gameState.idGenerator = func(i int) string { return ids[i] }
+	return nil
+}
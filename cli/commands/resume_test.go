@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/stretchr/testify/require"
+)
+
+const resumeExport = `{"id":"GAME_ID","ruleset":{"name":"wrapped","version":"cli","settings":{"foodSpawnChance":11,"minimumFood":7,"hazardDamagePerTurn":19,"royale":{"shrinkEveryNTurns":17}}},"map":"empty","timeout":500,"source":"","seed":1234}
+{"game":{"id":"GAME_ID"},"turn":0,"board":{"height":7,"width":9,"snakes":[{"id":"one","name":"ONE","health":100,"body":[{"x":1,"y":1}]},{"id":"two","name":"TWO","health":100,"body":[{"x":5,"y":5}]}],"food":[],"hazards":[]}}
+{"game":{"id":"GAME_ID"},"turn":1,"board":{"height":7,"width":9,"snakes":[{"id":"one","name":"ONE","health":99,"body":[{"x":1,"y":2}]},{"id":"two","name":"TWO","health":99,"body":[{"x":5,"y":6}]}],"food":[{"x":3,"y":3}],"hazards":[]}}
+{"game":{"id":"GAME_ID"},"turn":2,"board":{"height":7,"width":9,"snakes":[{"id":"one","name":"ONE","health":98,"body":[{"x":1,"y":3}]}],"food":[],"hazards":[]},"pointState":[{"x":4,"y":4,"value":2}]}
+`
+
+func TestResumeFromExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(resumeExport), 0644))
+
+	gameState := buildDefaultGameState()
+	gameState.ResumePath = path
+	gameState.ResumeTurn = 1
+	gameState.Names = []string{"TWO"}
+	gameState.URLs = []string{"http://example.com/2", "http://example.com/1"}
+	require.NoError(t, gameState.Initialize())
+
+	require.Equal(t, rules.GameTypeWrapped, gameState.GameType)
+	require.Equal(t, "empty", gameState.gameMap.ID())
+	require.Equal(t, int64(1234), gameState.Seed)
+	require.Equal(t, 9, gameState.Width)
+	require.Equal(t, 7, gameState.Height)
+	require.Equal(t, 17, gameState.ruleset.Settings().Int(rules.ParamShrinkEveryNTurns, 0))
+	require.Equal(t, 1, gameState.resumeBoardState.Turn)
+	require.Equal(t, []rules.Point{{X: 3, Y: 3}}, gameState.resumeBoardState.Food)
+
+	// snakes are matched by name first, then by order
+	require.Equal(t, "two", gameState.idGenerator(0))
+	require.Equal(t, "one", gameState.idGenerator(1))
+	require.Equal(t, []string{"TWO", "ONE"}, gameState.Names)
+
+	// the last turn is used by default
+	gameState = buildDefaultGameState()
+	gameState.ResumePath = path
+	gameState.ResumeTurn = -1
+	gameState.URLs = []string{"http://example.com/1"}
+	require.NoError(t, gameState.Initialize())
+	require.Equal(t, 2, gameState.resumeBoardState.Turn)
+	require.Equal(t, map[rules.Point]int{{X: 4, Y: 4}: 2}, gameState.resumeBoardState.PointState)
+
+	gameState = buildDefaultGameState()
+	gameState.ResumePath = path
+	gameState.ResumeTurn = 5
+	gameState.URLs = []string{"http://example.com/1"}
+	require.EqualError(t, gameState.Initialize(), "Failed to load game to resume: turn 5 was not recorded")
+
+	gameState = buildDefaultGameState()
+	gameState.ResumePath = path
+	gameState.ResumeTurn = 0
+	gameState.URLs = []string{"http://example.com/1"}
+	require.EqualError(t, gameState.Initialize(), "Failed to load game to resume: the resumed board has 2 snakes, but 1 URLs were provided")
+}
+
+func TestResumeMapParams(t *testing.T) {
+	export := strings.Replace(resumeExport, `"map":"empty"`, `"map":"sinkholes","mapParams":{"sinkholes.maxRings":"2"}`, 1)
+	path := filepath.Join(t.TempDir(), "game.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(export), 0644))
+
+	gameState := buildDefaultGameState()
+	gameState.ResumePath = path
+	gameState.Params = []string{"sinkholes.maxRings=4"}
+	gameState.ResumeTurn = -1
+	gameState.URLs = []string{"http://example.com/1"}
+	require.NoError(t, gameState.Initialize())
+	require.Equal(t, map[string]string{"sinkholes.maxRings": "2"}, gameState.mapParams)
+	require.Equal(t, 19, gameState.ruleset.Settings().Int(rules.ParamHazardDamagePerTurn, 0))
+}
+
+func TestResumeFromBoardState(t *testing.T) {
+	boardState := rules.NewBoardState(11, 11).
+		WithTurn(140).
+		WithSnakes([]rules.Snake{
+			{ID: "one", Body: []rules.Point{{X: 1, Y: 1}}, Health: 50},
+			{ID: "dead", Body: []rules.Point{{X: 2, Y: 2}}, EliminatedCause: rules.EliminatedByCollision},
+			{ID: "two", Body: []rules.Point{{X: 3, Y: 3}}, Health: 60},
+		}).
+		WithPointState(map[rules.Point]int{{X: 4, Y: 4}: 2})
+	data, err := json.Marshal(serializedBoardState{
+		Turn:       boardState.Turn,
+		Height:     boardState.Height,
+		Width:      boardState.Width,
+		Food:       boardState.Food,
+		Snakes:     boardState.Snakes,
+		Hazards:    boardState.Hazards,
+		PointState: exportPointState(boardState.PointState),
+	})
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"PointState":[{"x":4,"y":4,"value":2}]`)
+	path := filepath.Join(t.TempDir(), "board.json")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	gameState := buildDefaultGameState()
+	gameState.ResumePath = path
+	gameState.URLs = []string{"http://example.com/1", "http://example.com/2"}
+	require.NoError(t, gameState.Initialize())
+
+	require.Equal(t, boardState, gameState.resumeBoardState)
+	require.Equal(t, "one", gameState.idGenerator(0))
+	require.Equal(t, "two", gameState.idGenerator(1))
+
+	gameState.snakeStates = map[string]SnakeState{"one": {ID: "one", URL: "http://example.com/1"}, "two": {ID: "two", URL: "http://example.com/2"}}
+	gameState.httpClient = stubHTTPClient{nil, 200, func(_ string) string { return "" }, 0}
+	gameOver, resumed, err := gameState.initializeBoardFromArgs()
+	require.NoError(t, err)
+	require.False(t, gameOver)
+	require.Equal(t, boardState, resumed)
+}
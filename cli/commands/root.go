@@ -24,10 +24,20 @@ var rootCmd = &cobra.Command{
 func Execute() {
 	rootCmd.AddCommand(NewPlayCommand())
 	rootCmd.AddCommand(NewMoveCommand())
+	rootCmd.AddCommand(NewReplayCommand())
+	rootCmd.AddCommand(NewVerifyCommand())
+	rootCmd.AddCommand(NewCheckCommand())
+	rootCmd.AddCommand(NewScenarioCommand())
+	rootCmd.AddCommand(NewRecordCommand())
+	rootCmd.AddCommand(NewDiffSnakeCommand())
+	rootCmd.AddCommand(NewCompareCommand())
+	rootCmd.AddCommand(NewRenderCommand())
 
 	mapCommand := NewMapCommand()
 	mapCommand.AddCommand(NewMapListCommand())
 	mapCommand.AddCommand(NewMapInfoCommand())
+	mapCommand.AddCommand(NewMapPreviewCommand())
+	mapCommand.AddCommand(NewMapValidateCommand())
 
 	rootCmd.AddCommand(mapCommand)
 
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules/scenario"
+	"github.com/spf13/cobra"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+type ScenarioState struct {
+	// Options
+	Dir       string
+	URL       string
+	Timeout   int
+	JUnitPath string
+
+	// Internal state
+	agent scenario.Agent
+}
+
+func NewScenarioCommand() *cobra.Command {
+	scenarioState := &ScenarioState{}
+
+	var scenarioCmd = &cobra.Command{
+		Use:   "scenario <dir>",
+		Short: "Check the moves a Battlesnake makes on a set of boards.",
+		Long: "Load every scenario file in a directory, send its move request to a Battlesnake, and check that the snake makes one of the allowed moves and none of the forbidden ones.\n" +
+			"Results can also be written as JUnit XML, for CI systems to report.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			scenarioState.Dir = args[0]
+			results, err := scenarioState.Run()
+			if err != nil {
+				log.ERROR.Fatalf("Error running scenarios: %v", err)
+			}
+			if !printScenarioResults(results) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	scenarioCmd.Flags().StringVarP(&scenarioState.URL, "url", "u", "", "URL of the Battlesnake to test")
+	scenarioCmd.Flags().IntVarP(&scenarioState.Timeout, "timeout", "t", 500, "Request Timeout")
+	scenarioCmd.Flags().StringVar(&scenarioState.JUnitPath, "junit", "", "File to write the results to as JUnit XML")
+	_ = scenarioCmd.MarkFlagRequired("url")
+
+	scenarioCmd.Flags().SortFlags = false
+
+	return scenarioCmd
+}
+
+// Run every scenario in the directory, and write the JUnit report if one was asked for.
+func (scenarioState *ScenarioState) Run() ([]scenario.Result, error) {
+	scenarios, err := scenario.LoadDir(scenarioState.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(scenarios) == 0 {
+		return nil, fmt.Errorf("no scenario files found in %s", scenarioState.Dir)
+	}
+
+	if scenarioState.agent == nil {
+		if scenarioState.Timeout <= 0 {
+			scenarioState.Timeout = 500
+		}
+		scenarioState.agent = scenario.HTTPAgent{
+			URL: scenarioState.URL,
+			Client: &http.Client{
+				Timeout: time.Duration(scenarioState.Timeout) * time.Millisecond,
+			},
+		}
+	}
+	results := scenario.Run(scenarioState.agent, scenarios)
+
+	if scenarioState.JUnitPath != "" {
+		if err := writeJUnitFile(scenarioState.JUnitPath, results); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// Write the results to a JUnit file. Errors closing the file are returned
+// too, since the report may not have been written in full.
+func writeJUnitFile(path string, results []scenario.Result) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("Unable to create JUnit file: %w", err)
+	}
+	if err := scenario.WriteJUnit(f, "scenarios", results); err != nil {
+		f.Close()
+		return fmt.Errorf("Unable to write JUnit file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("Unable to write JUnit file: %w", err)
+	}
+	return nil
+}
+
+// Print a line for each scenario, followed by a summary. Returns whether every scenario passed.
+func printScenarioResults(results []scenario.Result) bool {
+	passed := 0
+	for _, result := range results {
+		status := "FAIL"
+		if result.Passed() {
+			status = "PASS"
+			passed++
+		}
+		fmt.Printf("%s  %-44s %-6s %5dms\n", status, result.Scenario.Name, result.Move, result.Duration.Milliseconds())
+		if result.Error != nil {
+			fmt.Printf("      - %v\n", result.Error)
+		} else if result.Failure != "" {
+			fmt.Printf("      - %s\n", result.Failure)
+		}
+	}
+	fmt.Printf("\n%d/%d scenarios passed\n", passed, len(results))
+	return passed == len(results)
+}
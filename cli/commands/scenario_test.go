@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/BattlesnakeOfficial/rules/scenario"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenarioRun(t *testing.T) {
+	junitPath := filepath.Join(t.TempDir(), "results.xml")
+	scenarioState := &ScenarioState{
+		Dir:       "../../scenario/testdata",
+		JUnitPath: junitPath,
+		agent: scenario.AgentFunc(func(request client.SnakeRequest) (client.MoveResponse, error) {
+			return client.MoveResponse{Move: "down"}, nil
+		}),
+	}
+	results, err := scenarioState.Run()
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.False(t, printScenarioResults(results))
+
+	junit, err := os.ReadFile(junitPath)
+	require.NoError(t, err)
+	require.Contains(t, string(junit), `failures="2"`)
+
+	_, err = (&ScenarioState{Dir: t.TempDir()}).Run()
+	require.ErrorContains(t, err, "no scenario files found")
+}
@@ -0,0 +1,334 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+// Keys understood by the terminal UI, as read from a terminal in raw mode.
+const (
+	keyLeft   = "\033[D"
+	keyRight  = "\033[C"
+	keyHome   = "\033[H"
+	keyEnd    = "\033[F"
+	keyCtrlC  = "\x03"
+	tuiFooter = "←/→ step  home/end first/last  space play/pause  q quit"
+)
+
+// A snapshot of a single turn shown by the terminal UI.
+type tuiTurn struct {
+	boardState  *rules.BoardState
+	snakeStates map[string]SnakeState
+}
+
+// A full-screen terminal UI for stepping through the turns of a game, while
+// it's being played or replayed.
+type terminalUI struct {
+	useColor bool
+	delay    time.Duration // time between turns while playing
+
+	mutex    sync.Mutex
+	turns    []tuiTurn
+	position int
+	playing  bool
+	status   string
+
+	input  io.Reader
+	output io.Writer
+
+	restore   func()
+	updated   chan struct{}
+	quit      chan struct{} // closed when the user quits
+	quitOnce  sync.Once
+	finished  chan struct{} // closed when Run returns
+	closeOnce sync.Once
+}
+
+func newTerminalUI(useColor bool, delay time.Duration) *terminalUI {
+	return &terminalUI{
+		useColor: useColor,
+		delay:    delay,
+		playing:  true,
+		input:    os.Stdin,
+		output:   os.Stdout,
+		updated:  make(chan struct{}, 1),
+		quit:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+}
+
+// Add a turn to the end of the game. The snake states are copied, so they can keep changing.
+func (ui *terminalUI) AddTurn(boardState *rules.BoardState, snakeStates map[string]SnakeState) {
+	snapshot := make(map[string]SnakeState, len(snakeStates))
+	for id, snakeState := range snakeStates {
+		snapshot[id] = snakeState
+	}
+
+	ui.mutex.Lock()
+	ui.turns = append(ui.turns, tuiTurn{boardState: boardState.Clone(), snakeStates: snapshot})
+	ui.mutex.Unlock()
+	ui.notify()
+}
+
+// Set the status line shown below the board, such as the result of the game.
+func (ui *terminalUI) SetStatus(status string) {
+	ui.mutex.Lock()
+	ui.status = status
+	ui.mutex.Unlock()
+	ui.notify()
+}
+
+// Returns a channel that's closed when the user quits.
+func (ui *terminalUI) Done() <-chan struct{} {
+	return ui.quit
+}
+
+func (ui *terminalUI) notify() {
+	select {
+	case ui.updated <- struct{}{}:
+	default:
+	}
+}
+
+// Take over the terminal. Run must be called afterwards to handle key presses.
+func (ui *terminalUI) Start() error {
+	restore, err := enableRawMode()
+	if err != nil {
+		return fmt.Errorf("Unable to start the terminal UI: %w", err)
+	}
+	ui.restore = restore
+
+	// Log messages would be drawn over the UI
+	log.SetStdoutOutput(io.Discard)
+	fmt.Fprint(ui.output, TERM_ALT_SCREEN+TERM_HIDE_CURSOR)
+	return nil
+}
+
+// Draw the game and handle key presses until the user quits or the UI is closed.
+func (ui *terminalUI) Run() {
+	defer close(ui.finished)
+
+	keys := make(chan string)
+	go ui.readKeys(keys)
+
+	for {
+		ui.draw()
+
+		var next <-chan time.Time
+		if ui.canAdvance() {
+			next = time.After(ui.delay)
+		}
+
+		select {
+		case key, ok := <-keys:
+			if !ok {
+				ui.stop()
+				return
+			}
+			ui.handleKey(key)
+		case <-ui.updated:
+		case <-next:
+			ui.step(1)
+		case <-ui.quit:
+			return
+		}
+	}
+}
+
+// Stop the UI if it's still running, and give the terminal back.
+func (ui *terminalUI) Close() {
+	ui.closeOnce.Do(func() {
+		ui.stop()
+		<-ui.finished
+		fmt.Fprint(ui.output, TERM_SHOW_CURSOR+TERM_MAIN_SCREEN)
+		log.SetStdoutOutput(os.Stderr)
+		if ui.restore != nil {
+			ui.restore()
+		}
+	})
+}
+
+// Read key presses, keeping escape sequences for the arrow keys together.
+func (ui *terminalUI) readKeys(keys chan<- string) {
+	defer close(keys)
+	reader := bufio.NewReader(ui.input)
+	buf := make([]byte, 16)
+	for {
+		n, err := reader.Read(buf)
+		if err != nil {
+			return
+		}
+		select {
+		case keys <- string(buf[:n]):
+		case <-ui.quit:
+			return
+		}
+	}
+}
+
+func (ui *terminalUI) handleKey(key string) {
+	switch key {
+	case "q", keyCtrlC:
+		ui.stop()
+	case "h", "a", keyLeft:
+		ui.setPlaying(false)
+		ui.step(-1)
+	case "l", "d", keyRight:
+		ui.setPlaying(false)
+		ui.step(1)
+	case "g", keyHome:
+		ui.setPlaying(false)
+		ui.jump(0)
+	case "G", keyEnd:
+		ui.setPlaying(true)
+		ui.jump(-1)
+	case " ":
+		ui.mutex.Lock()
+		ui.playing = !ui.playing
+		ui.mutex.Unlock()
+	}
+}
+
+func (ui *terminalUI) stop() {
+	ui.quitOnce.Do(func() { close(ui.quit) })
+}
+
+func (ui *terminalUI) setPlaying(playing bool) {
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+
+	ui.playing = playing
+}
+
+// Whether playing should move on to the next turn.
+func (ui *terminalUI) canAdvance() bool {
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+
+	return ui.playing && ui.position < len(ui.turns)-1
+}
+
+func (ui *terminalUI) step(delta int) {
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+
+	ui.position += delta
+	if ui.position >= len(ui.turns) {
+		ui.position = len(ui.turns) - 1
+	}
+	if ui.position < 0 {
+		ui.position = 0
+	}
+}
+
+// Jump to a turn index, or to the latest turn when the index is negative.
+func (ui *terminalUI) jump(index int) {
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+
+	if index < 0 || index >= len(ui.turns) {
+		index = len(ui.turns) - 1
+	}
+	if index < 0 {
+		index = 0
+	}
+	ui.position = index
+}
+
+func (ui *terminalUI) draw() {
+	fmt.Fprint(ui.output, TERM_CLEAR_SCREEN+strings.ReplaceAll(ui.render(), "\n", "\r\n"))
+}
+
+// Render the current turn, with the board on the left and a sidebar describing each snake on the right.
+func (ui *terminalUI) render() string {
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+
+	var o strings.Builder
+	if len(ui.turns) == 0 {
+		o.WriteString("Waiting for the game to start...\n")
+		return o.String()
+	}
+	turn := ui.turns[ui.position]
+	lastTurn := ui.turns[len(ui.turns)-1].boardState.Turn
+
+	state := "paused"
+	if ui.playing {
+		state = "playing"
+	}
+	o.WriteString(fmt.Sprintf("Turn %d/%d (%s)\n\n", turn.boardState.Turn, lastTurn, state))
+
+	renderer := &GameState{UseColor: ui.useColor, snakeStates: turn.snakeStates}
+	boardRows := renderer.renderBoard(turn.boardState, true)
+	sidebar := ui.renderSidebar(turn)
+	for i := 0; i < len(boardRows) || i < len(sidebar); i++ {
+		if i < len(boardRows) {
+			o.WriteString(boardRows[i])
+		} else {
+			o.WriteString(strings.Repeat(" ", turn.boardState.Width))
+		}
+		if i < len(sidebar) {
+			o.WriteString("   ")
+			o.WriteString(sidebar[i])
+		}
+		o.WriteString("\n")
+	}
+
+	o.WriteString("\n")
+	if ui.status != "" {
+		o.WriteString(ui.status + "\n")
+	}
+	o.WriteString(tuiFooter + "\n")
+	return o.String()
+}
+
+func (ui *terminalUI) renderSidebar(turn tuiTurn) []string {
+	var lines []string
+	for _, snake := range turn.boardState.Snakes {
+		snakeState := turn.snakeStates[snake.ID]
+		eliminated := snake.EliminatedCause != rules.NotEliminated
+
+		var name string
+		if ui.useColor {
+			red, green, blue := parseSnakeColor(snakeState.Color)
+			name = fmt.Sprintf(TERM_FG_RGB+"■■"+TERM_RESET+" "+TERM_BOLD+"%s"+TERM_RESET, red, green, blue, snakeState.Name)
+		} else {
+			name = fmt.Sprintf("%c %s", snakeState.Character, snakeState.Name)
+		}
+		lines = append(lines, name)
+
+		if eliminated {
+			line := fmt.Sprintf("  Eliminated on turn %d: %s", snake.EliminatedOnTurn, snake.EliminatedCause)
+			if snake.EliminatedBy != "" {
+				line += " by " + turn.snakeStates[snake.EliminatedBy].Name
+			}
+			if ui.useColor && snake.EliminatedOnTurn == turn.boardState.Turn {
+				line = TERM_FG_ELIMINATED + line + TERM_RESET
+			}
+			lines = append(lines, line)
+		} else {
+			lines = append(lines, fmt.Sprintf("  Health %d  Length %d", snake.Health, len(snake.Body)))
+			lines = append(lines, fmt.Sprintf("  Latency %dms  Move %s", snakeState.Latency.Milliseconds(), snakeState.LastMove))
+			if snakeState.Shout != "" {
+				lines = append(lines, fmt.Sprintf("  %q", snakeState.Shout))
+			}
+			if snakeState.Error != nil {
+				line := fmt.Sprintf("  Error: %v", snakeState.Error)
+				if ui.useColor {
+					line = TERM_FG_ELIMINATED + line + TERM_RESET
+				}
+				lines = append(lines, line)
+			}
+		}
+		lines = append(lines, "")
+	}
+	return lines
+}
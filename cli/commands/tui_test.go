@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminalUIRender(t *testing.T) {
+	ui := newTerminalUI(false, 0)
+	require.Contains(t, ui.render(), "Waiting for the game to start")
+
+	snakeStates := map[string]SnakeState{
+		"one": {ID: "one", Name: "ONE", Character: '■', LastMove: rules.MoveUp, Latency: 42 * time.Millisecond, Shout: "hello"},
+		"two": {ID: "two", Name: "TWO", Character: '⌀', LastMove: rules.MoveDown, Error: errors.New("timed out")},
+	}
+	first := rules.NewBoardState(3, 3).WithTurn(0).WithSnakes([]rules.Snake{
+		{ID: "one", Body: []rules.Point{{X: 0, Y: 0}, {X: 0, Y: 0}}, Health: 100},
+		{ID: "two", Body: []rules.Point{{X: 2, Y: 2}, {X: 2, Y: 2}}, Health: 100},
+	})
+	ui.AddTurn(first, snakeStates)
+
+	second := first.Clone().WithTurn(1)
+	second.Snakes[1].EliminatedCause = rules.EliminatedByOutOfBounds
+	second.Snakes[1].EliminatedOnTurn = 1
+	ui.AddTurn(second, snakeStates)
+
+	// changing the snake states after adding a turn shouldn't change the turn
+	snakeStates["one"] = SnakeState{}
+
+	rendered := ui.render()
+	require.Contains(t, rendered, "Turn 0/1 (playing)")
+	require.Contains(t, rendered, "■ ONE")
+	require.Contains(t, rendered, "Health 100  Length 2")
+	require.Contains(t, rendered, "Latency 42ms  Move up")
+	require.Contains(t, rendered, `"hello"`)
+	require.Contains(t, rendered, "Error: timed out")
+	require.NotContains(t, rendered, "✕")
+
+	ui.handleKey(keyRight)
+	rendered = ui.render()
+	require.Contains(t, rendered, "Turn 1/1 (paused)")
+	require.Contains(t, rendered, "Eliminated on turn 1: wall-collision")
+	require.Contains(t, rendered, "✕")
+
+	ui.handleKey(keyRight)
+	require.Equal(t, 1, ui.position)
+	ui.handleKey("h")
+	require.Equal(t, 0, ui.position)
+	ui.handleKey("G")
+	require.Equal(t, 1, ui.position)
+	require.True(t, ui.playing)
+
+	ui.SetStatus("Game completed after 1 turns.")
+	require.Contains(t, ui.render(), "Game completed after 1 turns.")
+
+	ui.handleKey("q")
+	select {
+	case <-ui.Done():
+	default:
+		require.Fail(t, "quitting should close the UI")
+	}
+}
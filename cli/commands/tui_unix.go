@@ -0,0 +1,47 @@
+//go:build !windows
+
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Put the terminal into raw mode, so that key presses are read as they're
+// typed and aren't echoed. Returns a function that restores the previous mode.
+func enableRawMode() (func(), error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() {
+		_, _ = stty(strings.TrimSpace(saved))
+	}, nil
+}
+
+// Put the terminal into cbreak mode, so that key presses are read as they're
+// typed and aren't echoed, but output and Ctrl-C work as usual. Returns a
+// function that restores the previous mode.
+func enableCbreakMode() (func(), error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return nil, err
+	}
+	return func() {
+		_, _ = stty(strings.TrimSpace(saved))
+	}, nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return string(output), err
+}
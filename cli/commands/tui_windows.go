@@ -0,0 +1,13 @@
+//go:build windows
+
+package commands
+
+import "errors"
+
+func enableRawMode() (func(), error) {
+	return nil, errors.New("the terminal UI isn't supported on Windows")
+}
+
+func enableCbreakMode() (func(), error) {
+	return nil, errors.New("reading single key presses isn't supported on Windows")
+}
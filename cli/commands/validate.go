@@ -0,0 +1,294 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/spf13/cobra"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+// Board sizes used to validate maps that can be played on any size.
+var unlimitedMapSizes = []maps.Dimensions{
+	{Width: rules.BoardSizeSmall, Height: rules.BoardSizeSmall},
+	{Width: rules.BoardSizeMedium, Height: rules.BoardSizeMedium},
+	{Width: rules.BoardSizeLarge, Height: rules.BoardSizeLarge},
+	{Width: rules.BoardSizeXXLarge, Height: rules.BoardSizeXXLarge},
+}
+
+type mapValidator struct {
+	Seed  int64
+	Seeds int
+	Turns int
+}
+
+// A board size, number of players and seed that a map is played with.
+type mapCombination struct {
+	Width   int
+	Height  int
+	Players int
+	Seed    int64
+}
+
+func (combination mapCombination) String() string {
+	players := "players"
+	if combination.Players == 1 {
+		players = "player"
+	}
+	return fmt.Sprintf("%dx%d, %d %s, seed %d", combination.Width, combination.Height, combination.Players, players, combination.Seed)
+}
+
+// A problem found with a map. Turn is -1 for problems found while setting up the board.
+type mapProblem struct {
+	Combination *mapCombination
+	Turn        int
+	Message     string
+}
+
+func (problem mapProblem) String() string {
+	if problem.Combination == nil {
+		return problem.Message
+	}
+	if problem.Turn < 0 {
+		return fmt.Sprintf("%s, setup: %s", problem.Combination, problem.Message)
+	}
+	return fmt.Sprintf("%s, turn %d: %s", problem.Combination, problem.Turn, problem.Message)
+}
+
+func NewMapValidateCommand() *cobra.Command {
+	validator := &mapValidator{}
+
+	var validateCmd = &cobra.Command{
+		Use:   "validate [flags] map_name|map_file [...map_name|map_file]",
+		Short: "Check that map(s) work with every board size and number of players they support",
+		Long: "Set up a board and play turns with each map, for every board size and number of players in the map's metadata and a range of seeds.\n" +
+			"Boards are checked for errors, overlapping snakes, food or hazards off the board, and duplicate food. Maps can be given by name, or as a JSON or YAML map file.",
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			failed := false
+			for i, arg := range args {
+				gameMap, err := loadMapArg(arg)
+				if err != nil {
+					log.ERROR.Fatalf("Failed to load game map %v: %v", arg, err)
+				}
+				if i > 0 {
+					fmt.Print("\n")
+				}
+				if !printMapProblems(gameMap, validator.Validate(gameMap), validator.combinations(gameMap.Meta())) {
+					failed = true
+				}
+			}
+			if failed {
+				os.Exit(1)
+			}
+		},
+	}
+
+	validateCmd.Flags().Int64VarP(&validator.Seed, "seed", "r", 1, "First seed to play each combination with")
+	validateCmd.Flags().IntVar(&validator.Seeds, "seeds", 10, "Number of seeds to play each combination with")
+	validateCmd.Flags().IntVar(&validator.Turns, "turns", 100, "Number of turns to play with each combination")
+
+	validateCmd.Flags().SortFlags = false
+
+	return validateCmd
+}
+
+// Load a map from a file if the argument is a JSON or YAML file, otherwise look it up by name.
+func loadMapArg(arg string) (maps.GameMap, error) {
+	switch strings.ToLower(filepath.Ext(arg)) {
+	case ".json", ".yaml", ".yml":
+		return maps.LoadFileMap(arg)
+	}
+	return maps.GetMap(arg)
+}
+
+// Every board size and number of players the map supports, with each seed.
+func (validator *mapValidator) combinations(meta maps.Metadata) []mapCombination {
+	boardSizes := []maps.Dimensions(meta.BoardSizes)
+	if meta.BoardSizes.IsUnlimited() {
+		boardSizes = unlimitedMapSizes
+	}
+
+	var combinations []mapCombination
+	for _, size := range boardSizes {
+		for players := meta.MinPlayers; players <= meta.MaxPlayers; players++ {
+			for i := 0; i < validator.Seeds; i++ {
+				combinations = append(combinations, mapCombination{
+					Width:   size.Width,
+					Height:  size.Height,
+					Players: players,
+					Seed:    validator.Seed + int64(i),
+				})
+			}
+		}
+	}
+	return combinations
+}
+
+// Play every combination the map supports, and return the problems found.
+// Only the first problem found with each combination is returned.
+func (validator *mapValidator) Validate(gameMap maps.GameMap) []mapProblem {
+	meta := gameMap.Meta()
+	var problems []mapProblem
+	if meta.Version < 1 {
+		problems = append(problems, mapProblem{Message: fmt.Sprintf("version %d should be at least 1", meta.Version)})
+	}
+	if len(meta.BoardSizes) == 0 {
+		problems = append(problems, mapProblem{Message: "no board sizes are declared"})
+	}
+	if meta.MaxPlayers < 1 || meta.MinPlayers > meta.MaxPlayers {
+		problems = append(problems, mapProblem{Message: fmt.Sprintf("invalid number of players %d-%d", meta.MinPlayers, meta.MaxPlayers)})
+	}
+
+	for _, combination := range validator.combinations(meta) {
+		combination := combination
+		if problem := validator.play(gameMap, &combination); problem != nil {
+			problems = append(problems, *problem)
+		}
+	}
+	return problems
+}
+
+// Set up a board with the map and play turns on it, returning the first problem found.
+func (validator *mapValidator) play(gameMap maps.GameMap, combination *mapCombination) (problem *mapProblem) {
+	turn := -1
+	fail := func(format string, args ...interface{}) *mapProblem {
+		return &mapProblem{Combination: combination, Turn: turn, Message: fmt.Sprintf(format, args...)}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			problem = fail("panic: %v", r)
+		}
+	}()
+
+	settings := mapSettings(combination.Seed)
+	snakeIDs := standInSnakeIDs(combination.Players)
+	boardState, err := setupMapBoard(gameMap, combination.Width, combination.Height, snakeIDs, settings)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if message := checkSetupBoard(boardState, snakeIDs); message != "" {
+		return fail("%s", message)
+	}
+
+	for turn = 0; turn < validator.Turns; turn++ {
+		boardState, err = updateMapBoard(gameMap, boardState, settings)
+		if err != nil {
+			return fail("%v", err)
+		}
+		if message := checkMapBoard(boardState); message != "" {
+			return fail("%s", message)
+		}
+	}
+	return nil
+}
+
+// The settings maps are played with when they're checked or previewed, which match play's defaults.
+func mapSettings(seed int64) rules.Settings {
+	return rules.NewSettingsWithParams(
+		rules.ParamFoodSpawnChance, "15",
+		rules.ParamMinimumFood, "1",
+		rules.ParamHazardDamagePerTurn, "14",
+		rules.ParamShrinkEveryNTurns, "25",
+	).WithSeed(seed)
+}
+
+// IDs for the snakes that stand in for players when a map is checked or previewed.
+func standInSnakeIDs(players int) []string {
+	snakeIDs := make([]string, players)
+	for i := range snakeIDs {
+		snakeIDs[i] = fmt.Sprintf("snake-%d", i+1)
+	}
+	return snakeIDs
+}
+
+// Create a board with the given snakes and let the map set it up.
+func setupMapBoard(gameMap maps.GameMap, width, height int, snakeIDs []string, settings rules.Settings) (*rules.BoardState, error) {
+	return maps.SetupBoardWithMap(gameMap, settings, width, height, snakeIDs)
+}
+
+// Apply the map's changes for one turn and advance to the next. Snakes stay
+// where they were placed, since only the map's changes are of interest.
+func updateMapBoard(gameMap maps.GameMap, boardState *rules.BoardState, settings rules.Settings) (*rules.BoardState, error) {
+	next, err := maps.PreUpdateBoard(gameMap, boardState, settings)
+	if err != nil {
+		return nil, err
+	}
+	next, err = maps.PostUpdateBoard(gameMap, next, settings)
+	if err != nil {
+		return nil, err
+	}
+	next.Turn++
+	return next, nil
+}
+
+// Check that every snake was placed on the board without overlapping another.
+func checkSetupBoard(boardState *rules.BoardState, snakeIDs []string) string {
+	if len(boardState.Snakes) != len(snakeIDs) {
+		return fmt.Sprintf("board has %d snakes, expected %d", len(boardState.Snakes), len(snakeIDs))
+	}
+	occupied := map[rules.Point]string{}
+	for _, snake := range boardState.Snakes {
+		if len(snake.Body) == 0 {
+			return fmt.Sprintf("%s wasn't placed", snake.ID)
+		}
+		for _, p := range snake.Body {
+			if !isOnMapBoard(boardState, p) {
+				return fmt.Sprintf("%s starts at (%d,%d), which is off the board", snake.ID, p.X, p.Y)
+			}
+			if other, ok := occupied[p]; ok && other != snake.ID {
+				return fmt.Sprintf("%s and %s both start at (%d,%d)", other, snake.ID, p.X, p.Y)
+			}
+			occupied[p] = snake.ID
+		}
+	}
+	return checkMapBoard(boardState)
+}
+
+// Check that the food and hazards placed by a map are on the board, and that food isn't duplicated.
+func checkMapBoard(boardState *rules.BoardState) string {
+	food := map[rules.Point]bool{}
+	for _, p := range boardState.Food {
+		if !isOnMapBoard(boardState, p) {
+			return fmt.Sprintf("food at (%d,%d) is off the board", p.X, p.Y)
+		}
+		if food[p] {
+			return fmt.Sprintf("food at (%d,%d) is duplicated", p.X, p.Y)
+		}
+		food[p] = true
+	}
+	for _, p := range boardState.Hazards {
+		if !isOnMapBoard(boardState, p) {
+			return fmt.Sprintf("hazard at (%d,%d) is off the board", p.X, p.Y)
+		}
+	}
+	return ""
+}
+
+func isOnMapBoard(boardState *rules.BoardState, p rules.Point) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X < boardState.Width && p.Y < boardState.Height
+}
+
+// Print the problems found with a map. Returns whether there weren't any.
+func printMapProblems(gameMap maps.GameMap, problems []mapProblem, combinations []mapCombination) bool {
+	failedCombinations := 0
+	for _, problem := range problems {
+		if problem.Combination != nil {
+			failedCombinations++
+		}
+	}
+
+	status := "PASS"
+	if len(problems) > 0 {
+		status = "FAIL"
+	}
+	fmt.Printf("%s  %s: %d/%d combinations passed\n", status, gameMap.ID(), len(combinations)-failedCombinations, len(combinations))
+	for _, problem := range problems {
+		fmt.Printf("      - %s\n", problem)
+	}
+	return len(problems) == 0
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+// A map with a problem that can be switched on, for testing validation.
+type brokenMap struct {
+	maps.StandardMap
+	overlap      bool
+	foodOffBoard int // turn to place food off the board on
+	duplicate    bool
+	panics       bool
+}
+
+func (m brokenMap) ID() string {
+	return "broken"
+}
+
+func (m brokenMap) Meta() maps.Metadata {
+	return maps.Metadata{
+		Name:       "Broken",
+		Version:    1,
+		MinPlayers: 1,
+		MaxPlayers: 2,
+		BoardSizes: maps.FixedSizes(maps.Dimensions{Width: 7, Height: 7}, maps.Dimensions{Width: 11, Height: 11}),
+	}
+}
+
+func (m brokenMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	if m.overlap {
+		for _, snake := range initialBoardState.Snakes {
+			editor.PlaceSnake(snake.ID, []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}}, 100)
+		}
+		return nil
+	}
+	return m.StandardMap.SetupBoard(initialBoardState, settings, editor)
+}
+
+func (m brokenMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	if m.foodOffBoard > 0 && lastBoardState.Turn == m.foodOffBoard && lastBoardState.Width == 11 {
+		editor.AddFood(rules.Point{X: 11, Y: 0})
+	}
+	if m.duplicate && len(lastBoardState.Food) > 0 {
+		editor.AddFood(lastBoardState.Food[0])
+	}
+	if m.panics && lastBoardState.Turn == 3 {
+		panic("out of sauce")
+	}
+	return nil
+}
+
+func TestMapValidate(t *testing.T) {
+	validator := &mapValidator{Seed: 1, Seeds: 2, Turns: 10}
+	require.Len(t, validator.combinations(brokenMap{}.Meta()), 8)
+	require.Len(t, validator.combinations(maps.StandardMap{}.Meta()), 10*16*2)
+	require.Len(t, validator.combinations(maps.Metadata{MinPlayers: 1, MaxPlayers: 1, BoardSizes: maps.AnySize()}), len(unlimitedMapSizes)*2)
+
+	require.Empty(t, validator.Validate(brokenMap{}))
+	require.Empty(t, validator.Validate(maps.StandardMap{}))
+
+	problems := validator.Validate(brokenMap{overlap: true})
+	require.Len(t, problems, 4)
+	require.Equal(t, "7x7, 2 players, seed 1, setup: snake-1 and snake-2 both start at (1,1)", problems[0].String())
+
+	problems = validator.Validate(brokenMap{foodOffBoard: 5})
+	require.Len(t, problems, 4)
+	require.Equal(t, "11x11, 1 player, seed 1, turn 5: food at (11,0) is off the board", problems[0].String())
+
+	problems = validator.Validate(brokenMap{duplicate: true})
+	require.Len(t, problems, 8)
+	require.Contains(t, problems[0].String(), "turn 0: food at")
+	require.Contains(t, problems[0].String(), "is duplicated")
+
+	problems = validator.Validate(brokenMap{panics: true})
+	require.Len(t, problems, 8)
+	require.Equal(t, "7x7, 1 player, seed 1, turn 3: panic: out of sauce", problems[0].String())
+	require.False(t, printMapProblems(brokenMap{}, problems, validator.combinations(brokenMap{}.Meta())))
+}
+
+func TestMapValidateFile(t *testing.T) {
+	gameMap, err := loadMapArg(filepath.Join("..", "..", "maps", "testdata", "corners.json"))
+	require.NoError(t, err)
+	require.Equal(t, "corners", gameMap.ID())
+
+	validator := &mapValidator{Seed: 1, Seeds: 3, Turns: 50}
+	problems := validator.Validate(gameMap)
+	require.Empty(t, problems)
+	require.True(t, printMapProblems(gameMap, problems, validator.combinations(gameMap.Meta())))
+
+	gameMap, err = loadMapArg("standard")
+	require.NoError(t, err)
+	require.Equal(t, "standard", gameMap.ID())
+
+	_, err = loadMapArg("missing")
+	require.Error(t, err)
+}
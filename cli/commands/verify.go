@@ -0,0 +1,365 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/spf13/cobra"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+// Kinds of differences found between a recorded turn and the simulated turn.
+const (
+	differenceSnakes   = "snakes"
+	differenceHazards  = "hazards"
+	differenceFood     = "food" // tolerated, since food is placed randomly
+	differenceGameOver = "game-over"
+)
+
+// Maximum number of snakes on a single turn whose move is searched for, when it can't be inferred.
+const maxUnknownMoves = 4
+
+type verifyDifference struct {
+	Turn   int
+	Kind   string
+	Detail string
+}
+
+func (d verifyDifference) String() string {
+	return fmt.Sprintf("Turn %d: %s differ: %s", d.Turn, d.Kind, d.Detail)
+}
+
+type VerifyState struct {
+	// Options
+	MapFile string
+	MapDir  string
+
+	// Internal state
+	export      *gameExport
+	ruleset     rules.Ruleset
+	gameMap     maps.GameMap
+	boardStates []*rules.BoardState
+}
+
+func NewVerifyCommand() *cobra.Command {
+	verifyState := &VerifyState{}
+
+	var verifyCmd = &cobra.Command{
+		Use:   "verify <file>",
+		Short: "Check that a recorded game is consistent with the rules.",
+		Long: "Check that a recorded game is consistent with the rules, by re-simulating every turn with the recorded ruleset and map.\n" +
+			"Moves are inferred from the snake bodies on consecutive turns. Differences in food are reported separately because food is placed randomly.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := verifyState.Load(args[0]); err != nil {
+				log.ERROR.Fatalf("Error loading game: %v", err)
+			}
+			foodDifferences, divergence, err := verifyState.Verify()
+			if err != nil {
+				log.ERROR.Fatalf("Error verifying game: %v", err)
+			}
+			for _, d := range foodDifferences {
+				log.WARN.Println(d)
+			}
+			if divergence != nil {
+				log.ERROR.Println(divergence)
+				os.Exit(1)
+			}
+			log.INFO.Printf("Verified %d turns of game %s, %d turns had different food", len(verifyState.boardStates), verifyState.export.Game.ID, len(foodDifferences))
+		},
+	}
+
+	verifyCmd.Flags().StringVar(&verifyState.MapFile, "map-file", "", "JSON or YAML file with the custom map the game was played on")
+	verifyCmd.Flags().StringVar(&verifyState.MapDir, "map-dir", "", "Directory of JSON or YAML custom map files, including the map the game was played on")
+
+	return verifyCmd
+}
+
+// Read the recorded game and build the ruleset and map it was played with.
+func (verifyState *VerifyState) Load(path string) error {
+	// Games played on custom maps need them to be loaded first
+	if _, err := loadCustomMaps(verifyState.MapDir, verifyState.MapFile); err != nil {
+		return err
+	}
+
+	export, err := readGameExportFile(path)
+	if err != nil {
+		return err
+	}
+	return verifyState.loadExport(export)
+}
+
+func (verifyState *VerifyState) loadExport(export *gameExport) error {
+	gameMap, err := maps.GetMap(export.mapID())
+	if err != nil {
+		return fmt.Errorf("Failed to load game map %#v: %v", export.mapID(), err)
+	}
+
+	if export.Seed == 0 {
+		log.WARN.Printf("Recorded game doesn't include a seed, expect food and hazard differences")
+	}
+
+	verifyState.export = export
+	verifyState.gameMap = gameMap
+	verifyState.ruleset = rules.NewRulesetBuilder().
+		WithSeed(export.Seed).
+		WithParams(paramsFromRulesetSettings(export.Game.Ruleset.Settings)).
+		WithParams(export.MapParams).
+		WithSolo(len(export.Turns[0].Board.Snakes) < 2).
+		NamedRuleset(export.Game.Ruleset.Name)
+	verifyState.boardStates = make([]*rules.BoardState, 0, len(export.Turns))
+	for _, turn := range export.Turns {
+		verifyState.boardStates = append(verifyState.boardStates, boardStateFromTurn(turn))
+	}
+	return nil
+}
+
+// Re-simulate every recorded turn, starting from the recorded state of the
+// previous turn each time. Returns the turns where only food differed, and
+// the first turn where the recorded game diverges from the rules.
+func (verifyState *VerifyState) Verify() ([]verifyDifference, *verifyDifference, error) {
+	var foodDifferences []verifyDifference
+
+	for i := 0; i+1 < len(verifyState.boardStates); i++ {
+		differences, err := verifyState.verifyTurn(i)
+		if err != nil {
+			return foodDifferences, nil, fmt.Errorf("turn %d: %w", verifyState.boardStates[i].Turn, err)
+		}
+		for _, d := range differences {
+			if d.Kind != differenceFood {
+				return foodDifferences, &d, nil
+			}
+			foodDifferences = append(foodDifferences, d)
+		}
+	}
+
+	if verifyState.export.Result != nil {
+		lastState := verifyState.boardStates[len(verifyState.boardStates)-1]
+		gameOver, _, err := verifyState.ruleset.Execute(lastState, defaultMoves(lastState))
+		if err != nil {
+			return foodDifferences, nil, err
+		}
+		if !gameOver {
+			return foodDifferences, &verifyDifference{
+				Turn:   lastState.Turn,
+				Kind:   differenceGameOver,
+				Detail: "the game was recorded as complete, but the rules would continue",
+			}, nil
+		}
+	}
+
+	return foodDifferences, nil, nil
+}
+
+// Simulate the turn at index i, and compare it to the recorded turn that follows.
+func (verifyState *VerifyState) verifyTurn(i int) ([]verifyDifference, error) {
+	previous := verifyState.boardStates[i]
+	expected := verifyState.boardStates[i+1]
+
+	moves, unknown := verifyState.inferMoves(i)
+	if len(unknown) > maxUnknownMoves {
+		unknown = unknown[:maxUnknownMoves]
+	}
+
+	// Search the possible moves of eliminated snakes, since they can't be
+	// inferred from the next turn. The first combination that matches is used.
+	var firstDifferences []verifyDifference
+	combinations := 1 << (2 * len(unknown))
+	allMoves := []string{rules.MoveUp, rules.MoveDown, rules.MoveLeft, rules.MoveRight}
+	for c := 0; c < combinations; c++ {
+		for j, index := range unknown {
+			moves[index].Move = allMoves[(c>>(2*j))&3]
+		}
+
+		actual, err := verifyState.simulate(previous, moves)
+		if err != nil {
+			return nil, err
+		}
+		differences := compareBoardStates(expected, actual)
+		if c == 0 {
+			firstDifferences = differences
+		}
+		if !hasRuleDifferences(differences) {
+			return differences, nil
+		}
+	}
+	return firstDifferences, nil
+}
+
+// Infer the move of each snake from the position of its head on the next
+// turn, or use the recorded move when the snake was eliminated. Returns the
+// indexes of the moves that couldn't be determined.
+func (verifyState *VerifyState) inferMoves(i int) ([]rules.SnakeMove, []int) {
+	previous := verifyState.boardStates[i]
+	next := verifyState.boardStates[i+1]
+	wrapped := strings.HasPrefix(verifyState.ruleset.Name(), rules.GameTypeWrapped)
+
+	recordedMoves := map[string]string{}
+	for _, move := range verifyState.export.Turns[i].Moves {
+		recordedMoves[move.ID] = move.Move
+	}
+	nextHeads := map[string]rules.Point{}
+	for _, snake := range next.Snakes {
+		nextHeads[snake.ID] = snake.Body[0]
+	}
+
+	var moves []rules.SnakeMove
+	var unknown []int
+	for _, snake := range previous.Snakes {
+		move := ""
+		if nextHead, ok := nextHeads[snake.ID]; ok {
+			move = inferMove(snake.Body[0], nextHead, previous.Width, previous.Height, wrapped)
+		}
+		if move == "" {
+			move = recordedMoves[snake.ID]
+		}
+		if move == "" {
+			unknown = append(unknown, len(moves))
+			move = rules.MoveUp
+		}
+		moves = append(moves, rules.SnakeMove{ID: snake.ID, Move: move})
+	}
+	return moves, unknown
+}
+
+// Apply a turn the same way the play command does.
+func (verifyState *VerifyState) simulate(previous *rules.BoardState, moves []rules.SnakeMove) (*rules.BoardState, error) {
+	settings := verifyState.ruleset.Settings()
+
+	boardState, err := maps.PreUpdateBoard(verifyState.gameMap, previous, settings)
+	if err != nil {
+		return nil, fmt.Errorf("Error pre-updating board with game map: %w", err)
+	}
+	_, boardState, err = verifyState.ruleset.Execute(boardState, moves)
+	if err != nil {
+		return nil, fmt.Errorf("Error updating board state from ruleset: %w", err)
+	}
+	boardState, err = maps.PostUpdateBoard(verifyState.gameMap, boardState, settings)
+	if err != nil {
+		return nil, fmt.Errorf("Error post-updating board with game map: %w", err)
+	}
+	boardState.Turn += 1
+	return boardState, nil
+}
+
+// Returns the move that takes a snake's head from one point to the next, or an empty string if there isn't one.
+func inferMove(head, nextHead rules.Point, width, height int, wrapped bool) string {
+	dx, dy := nextHead.X-head.X, nextHead.Y-head.Y
+	if wrapped {
+		// Moving across the edge of the board looks like moving the whole way across it
+		if dx == width-1 {
+			dx = -1
+		} else if dx == 1-width {
+			dx = 1
+		}
+		if dy == height-1 {
+			dy = -1
+		} else if dy == 1-height {
+			dy = 1
+		}
+	}
+	switch {
+	case dx == 0 && dy == 1:
+		return rules.MoveUp
+	case dx == 0 && dy == -1:
+		return rules.MoveDown
+	case dx == -1 && dy == 0:
+		return rules.MoveLeft
+	case dx == 1 && dy == 0:
+		return rules.MoveRight
+	}
+	return ""
+}
+
+func defaultMoves(boardState *rules.BoardState) []rules.SnakeMove {
+	moves := make([]rules.SnakeMove, 0, len(boardState.Snakes))
+	for _, snake := range boardState.Snakes {
+		moves = append(moves, rules.SnakeMove{ID: snake.ID, Move: rules.MoveUp})
+	}
+	return moves
+}
+
+func hasRuleDifferences(differences []verifyDifference) bool {
+	for _, d := range differences {
+		if d.Kind != differenceFood {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare a recorded board state with a simulated one. Only snakes that are
+// still alive are compared, since eliminated snakes aren't recorded.
+func compareBoardStates(expected, actual *rules.BoardState) []verifyDifference {
+	var differences []verifyDifference
+	addDifference := func(kind string, format string, args ...interface{}) {
+		differences = append(differences, verifyDifference{
+			Turn:   expected.Turn,
+			Kind:   kind,
+			Detail: fmt.Sprintf(format, args...),
+		})
+	}
+
+	actualSnakes := map[string]rules.Snake{}
+	for _, snake := range actual.Snakes {
+		if snake.EliminatedCause == rules.NotEliminated {
+			actualSnakes[snake.ID] = snake
+		}
+	}
+	for _, expectedSnake := range expected.Snakes {
+		actualSnake, ok := actualSnakes[expectedSnake.ID]
+		if !ok {
+			addDifference(differenceSnakes, "snake %s was recorded alive, but would have been eliminated", expectedSnake.ID)
+			continue
+		}
+		delete(actualSnakes, expectedSnake.ID)
+		if !pointsEqual(expectedSnake.Body, actualSnake.Body) {
+			addDifference(differenceSnakes, "snake %s was recorded with body %v, but would have body %v", expectedSnake.ID, expectedSnake.Body, actualSnake.Body)
+		}
+		if expectedSnake.Health != actualSnake.Health {
+			addDifference(differenceSnakes, "snake %s was recorded with health %d, but would have health %d", expectedSnake.ID, expectedSnake.Health, actualSnake.Health)
+		}
+	}
+	for _, snake := range actual.Snakes {
+		if _, ok := actualSnakes[snake.ID]; ok {
+			addDifference(differenceSnakes, "snake %s was recorded as eliminated, but would still be alive", snake.ID)
+		}
+	}
+
+	if !pointsEqual(sortedPoints(expected.Hazards), sortedPoints(actual.Hazards)) {
+		addDifference(differenceHazards, "recorded %v, but expected %v", expected.Hazards, actual.Hazards)
+	}
+	if !pointsEqual(sortedPoints(expected.Food), sortedPoints(actual.Food)) {
+		addDifference(differenceFood, "recorded %v, but expected %v", expected.Food, actual.Food)
+	}
+
+	return differences
+}
+
+func sortedPoints(points []rules.Point) []rules.Point {
+	sorted := append([]rules.Point(nil), points...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].X != sorted[j].X {
+			return sorted[i].X < sorted[j].X
+		}
+		return sorted[i].Y < sorted[j].Y
+	})
+	return sorted
+}
+
+// Compare the positions of two lists of points.
+func pointsEqual(a, b []rules.Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].X != b[i].X || a[i].Y != b[i].Y {
+			return false
+		}
+	}
+	return true
+}
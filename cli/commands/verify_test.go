@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+// Play a short game against stub snakes and return the recorded export.
+func playRecordedGame(t *testing.T) *gameExport {
+	return playRecordedGameOnMap(t, "standard")
+}
+
+func playRecordedGameOnMap(t *testing.T, mapName string) *gameExport {
+	gameState := buildDefaultGameState()
+	gameState.MapName = mapName
+	gameState.Width = 7
+	gameState.Height = 7
+	gameState.URLs = []string{"http://example.com/1", "http://example.com/2"}
+	require.NoError(t, gameState.Initialize())
+
+	gameState.gameID = "GAME_ID"
+	gameState.idGenerator = func(index int) string { return fmt.Sprintf("snk_%d", index) }
+	gameState.httpClient = stubHTTPClient{nil, http.StatusOK, func(url string) string {
+		if url == "http://example.com/1/move" {
+			return `{"move": "up"}`
+		}
+		return `{"move": "down"}`
+	}, time.Millisecond}
+	outputFile := new(closableBuffer)
+	gameState.outputFile = outputFile
+
+	require.NoError(t, gameState.Run())
+
+	export, err := readGameExport(outputFile)
+	require.NoError(t, err)
+	return export
+}
+
+func TestVerifyRecordedGame(t *testing.T) {
+	export := playRecordedGame(t)
+	require.Greater(t, len(export.Turns), 2)
+
+	verifyState := &VerifyState{}
+	require.NoError(t, verifyState.loadExport(export))
+	foodDifferences, divergence, err := verifyState.Verify()
+	require.NoError(t, err)
+	require.Empty(t, foodDifferences)
+	require.Nil(t, divergence)
+}
+
+func TestVerifyDivergence(t *testing.T) {
+	export := playRecordedGame(t)
+
+	// food that was placed differently is tolerated
+	export.Turns[2].Board.Food = nil
+	// a snake with the wrong health isn't
+	export.Turns[2].Board.Snakes[0].Health += 10
+
+	verifyState := &VerifyState{}
+	require.NoError(t, verifyState.loadExport(export))
+	foodDifferences, divergence, err := verifyState.Verify()
+	require.NoError(t, err)
+	require.Len(t, foodDifferences, 0)
+	require.NotNil(t, divergence)
+	require.Equal(t, 2, divergence.Turn)
+	require.Equal(t, differenceSnakes, divergence.Kind)
+
+	export = playRecordedGame(t)
+	export.Turns[2].Board.Food = nil
+	require.NoError(t, verifyState.loadExport(export))
+	foodDifferences, divergence, err = verifyState.Verify()
+	require.NoError(t, err)
+	require.Nil(t, divergence)
+	require.NotEmpty(t, foodDifferences)
+	require.Equal(t, differenceFood, foodDifferences[0].Kind)
+}
+
+func TestInferMove(t *testing.T) {
+	head := rules.Point{X: 0, Y: 3}
+	require.Equal(t, rules.MoveUp, inferMove(head, rules.Point{X: 0, Y: 4}, 7, 7, false))
+	require.Equal(t, rules.MoveRight, inferMove(head, rules.Point{X: 1, Y: 3}, 7, 7, false))
+	require.Equal(t, "", inferMove(head, rules.Point{X: 6, Y: 3}, 7, 7, false))
+	require.Equal(t, rules.MoveLeft, inferMove(head, rules.Point{X: 6, Y: 3}, 7, 7, true))
+}
+
+// The standard map as it was at version 1.
+type standardMapV1 struct {
+	maps.StandardMap
+}
+
+func (m standardMapV1) Meta() maps.Metadata {
+	meta := m.StandardMap.Meta()
+	meta.Version = 1
+	return meta
+}
+
+func TestVerifyMapVersion(t *testing.T) {
+	export := playRecordedGame(t)
+	require.Equal(t, "standard@2", export.ResolvedMap)
+
+	// the version of the map that was recorded is used
+	export.ResolvedMap = "standard@1"
+	verifyState := &VerifyState{}
+	require.EqualError(t, verifyState.loadExport(export), `Failed to load game map "standard@1": map not found: standard doesn't have version 1`)
+	maps.TestMap("standard@1", standardMapV1{}, func() {
+		require.NoError(t, verifyState.loadExport(export))
+		require.Equal(t, 1, verifyState.gameMap.Meta().Version)
+	})
+}
+
+func TestVerifyMapState(t *testing.T) {
+	// snail mode keeps the tails of snakes in its map state, which is needed to verify each turn
+	export := playRecordedGameOnMap(t, "snail_mode")
+	require.Equal(t, "snail_mode@2", export.ResolvedMap)
+	require.Contains(t, export.Turns[2].GameState, "snail_mode.tails")
+	require.NotEmpty(t, export.Turns[3].Board.Hazards)
+
+	verifyState := &VerifyState{}
+	require.NoError(t, verifyState.loadExport(export))
+	_, divergence, err := verifyState.Verify()
+	require.NoError(t, err)
+	require.Nil(t, divergence)
+
+	for i := range export.Turns {
+		export.Turns[i].GameState = nil
+	}
+	require.NoError(t, verifyState.loadExport(export))
+	_, divergence, err = verifyState.Verify()
+	require.NoError(t, err)
+	require.NotNil(t, divergence)
+	require.Equal(t, differenceHazards, divergence.Kind)
+}
+
+func TestVerifyCustomMap(t *testing.T) {
+	mapDir := t.TempDir()
+	mapFile := `{"id": "verify_custom", "name": "Verify Custom", "boardSizes": [{"width": 7, "height": 7}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(mapDir, "verify_custom.json"), []byte(mapFile), 0644))
+	exportPath := filepath.Join(t.TempDir(), "game.jsonl")
+	export := strings.Replace(replayExport, `"map":"standard"`, `"map":"verify_custom"`, 1)
+	require.NoError(t, os.WriteFile(exportPath, []byte(export), 0644))
+
+	// the map has to be loaded to verify the game
+	require.Error(t, (&VerifyState{}).Load(exportPath))
+
+	verifyState := &VerifyState{MapDir: mapDir}
+	require.NoError(t, verifyState.Load(exportPath))
+	require.Equal(t, "verify_custom", verifyState.gameMap.ID())
+}
@@ -6,8 +6,16 @@ import (
 
 type ArcadeMazeMap struct{}
 
+var arcadeMazeGhosts = Parameter{
+	Name:        "arcade_maze.ghosts",
+	Type:        ParameterTypeBool,
+	Default:     "false",
+	Description: "Add two ghosts that wander the maze every other turn. Ghosts are hazards, so they're deadly with the map's default settings",
+}
+
 func init() {
 	globalRegistry.RegisterMap("arcade_maze", ArcadeMazeMap{})
+	globalRegistry.RegisterMapVersion("arcade_maze", arcadeMazeMapV1{})
 }
 
 func (m ArcadeMazeMap) ID() string {
@@ -19,11 +27,16 @@ func (m ArcadeMazeMap) Meta() Metadata {
 		Name:        "Arcade Maze",
 		Description: "Generic arcade maze map with deadly hazard walls.",
 		Author:      "Battlesnake",
-		Version:     1,
+		Version:     2,
 		MinPlayers:  1,
 		MaxPlayers:  6,
 		BoardSizes:  FixedSizes(Dimensions{19, 21}),
 		Tags:        []string{TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+		// The walls are meant to eliminate snakes that move into them
+		DefaultSettings: map[string]string{
+			rules.ParamHazardDamagePerTurn: "100",
+		},
+		Parameters: []Parameter{arcadeMazeGhosts},
 	}
 }
 
@@ -68,6 +81,24 @@ func (m ArcadeMazeMap) SetupBoard(initialBoardState *rules.BoardState, settings
 		editor.AddFood(rules.Point{X: 9, Y: 11})
 	}
 
+	// Place ghosts in the bottom corners, away from the snakes
+	if arcadeMazeGhosts.Bool(settings) {
+		if err := SetEntityWalls(editor, ArcadeMazeHazards); err != nil {
+			return err
+		}
+		ghosts := []Entity{
+			{ID: "ghost-1", Body: []rules.Point{{X: 1, Y: 1}}},
+			{ID: "ghost-2", Body: []rules.Point{{X: 17, Y: 1}}},
+		}
+		for _, ghost := range ghosts {
+			ghost.Path = EntityPathWander
+			ghost.Period = 2
+			if err := PlaceEntity(editor, ghost); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
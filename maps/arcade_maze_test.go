@@ -65,3 +65,74 @@ func TestArcadeMazeMap(t *testing.T) {
 		}
 	}
 }
+
+func TestArcadeMazeGhosts(t *testing.T) {
+	m := maps.ArcadeMazeMap{}
+	settings := rules.NewSettingsWithParams("arcade_maze.ghosts", "true").WithSeed(42)
+	boardState, err := maps.SetupBoardWithMap(m, settings, 19, 21, []string{"one"})
+	require.NoError(t, err)
+	require.Len(t, boardState.Hazards, len(maps.ArcadeMazeHazards)+2)
+
+	walls := map[rules.Point]bool{}
+	for _, p := range maps.ArcadeMazeHazards {
+		walls[p] = true
+	}
+	moves := 0
+	for turn := 0; turn < 50; turn++ {
+		boardState.Turn = turn
+		previous, err := maps.Entities(boardState)
+		require.NoError(t, err)
+		boardState, err = maps.PostUpdateBoard(m, boardState, settings)
+		require.NoError(t, err)
+
+		// ghosts move one cell every other turn, and stay out of the walls
+		ghosts, err := maps.Entities(boardState)
+		require.NoError(t, err)
+		require.Len(t, ghosts, 2)
+		for i, ghost := range ghosts {
+			head, previousHead := ghost.Body[0], previous[i].Body[0]
+			require.False(t, walls[head], "ghost in wall at %v", head)
+			distance := abs(head.X-previousHead.X) + abs(head.Y-previousHead.Y)
+			if turn%2 == 0 {
+				require.Equal(t, 1, distance)
+				moves++
+			} else {
+				require.Equal(t, 0, distance)
+			}
+			require.Contains(t, boardState.Hazards, head)
+		}
+		require.Len(t, boardState.Hazards, len(maps.ArcadeMazeHazards)+2)
+	}
+	require.Equal(t, 50, moves)
+
+	// there are no ghosts by default
+	boardState, err = maps.SetupBoardWithMap(m, rules.Settings{}, 19, 21, []string{"one"})
+	require.NoError(t, err)
+	ghosts, err := maps.Entities(boardState)
+	require.NoError(t, err)
+	require.Empty(t, ghosts)
+}
+
+func TestArcadeMazeMapV1(t *testing.T) {
+	v1, err := maps.GetMap("arcade_maze@1")
+	require.NoError(t, err)
+	require.Equal(t, 1, v1.Meta().Version)
+	require.Empty(t, v1.Meta().DefaultSettings, "version 1 used the ruleset's hazard damage")
+	require.Empty(t, v1.Meta().Parameters, "ghosts were added in version 2")
+	require.Equal(t, 2, maps.ArcadeMazeMap{}.Meta().Version)
+
+	// version 1 sets up the same board
+	settings := rules.Settings{}.WithSeed(42)
+	v1Board, err := maps.SetupBoardWithMap(v1, settings, 19, 21, []string{"one", "two"})
+	require.NoError(t, err)
+	v2Board, err := maps.SetupBoardWithMap(maps.ArcadeMazeMap{}, settings, 19, 21, []string{"one", "two"})
+	require.NoError(t, err)
+	require.Equal(t, v2Board, v1Board)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,21 @@
+package maps
+
+// Version 1 of the arcade maze, which was played with the ruleset's hazard
+// damage and didn't have ghosts. Kept so that games played with it can be
+// replayed.
+type arcadeMazeMapV1 struct {
+	ArcadeMazeMap
+}
+
+func (m arcadeMazeMapV1) Meta() Metadata {
+	return Metadata{
+		Name:        "Arcade Maze",
+		Description: "Generic arcade maze map with deadly hazard walls.",
+		Author:      "Battlesnake",
+		Version:     1,
+		MinPlayers:  1,
+		MaxPlayers:  6,
+		BoardSizes:  FixedSizes(Dimensions{19, 21}),
+		Tags:        []string{TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+	}
+}
@@ -0,0 +1,268 @@
+package maps
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// CompositeMapSeparator joins the IDs of the layers in the ID of a composite map.
+const CompositeMapSeparator = "+"
+
+// The GameState key the hazards placed by each layer of a composite map are stored in between turns.
+const compositeHazardsKey = "compositeMap.hazards"
+
+// CompositeMap combines the behaviour of other maps, which are applied to the
+// board in order as layers.
+//
+// Each layer owns a part of the board:
+//   - Snakes are placed by the first layer tagged with TAG_SNAKE_PLACEMENT, or the first layer if none are.
+//   - Food is placed by the first layer tagged with TAG_FOOD_PLACEMENT, or the first layer if none are.
+//   - Every layer places its own hazards, and the board has the hazards from all layers.
+//
+// Changes a layer makes to parts of the board it doesn't own are discarded.
+// Each layer only sees its own hazards, so layers that clear or remove hazards
+// don't affect the hazards of other layers.
+type CompositeMap struct {
+	layers      []GameMap
+	snakesOwner int
+	foodOwner   int
+}
+
+// NewCompositeMap combines maps into a single map, applied in the order given.
+func NewCompositeMap(layers ...GameMap) (*CompositeMap, error) {
+	if len(layers) < 2 {
+		return nil, fmt.Errorf("a composite map needs at least 2 layers, got %d", len(layers))
+	}
+	m := &CompositeMap{
+		layers:      layers,
+		snakesOwner: layerWithTag(layers, TAG_SNAKE_PLACEMENT),
+		foodOwner:   layerWithTag(layers, TAG_FOOD_PLACEMENT),
+	}
+	meta := m.Meta()
+	if len(meta.BoardSizes) == 0 {
+		return nil, fmt.Errorf("maps in %s don't have any board sizes in common", m.ID())
+	}
+	if meta.MinPlayers > meta.MaxPlayers {
+		return nil, fmt.Errorf("maps in %s don't support any of the same numbers of players", m.ID())
+	}
+	return m, nil
+}
+
+// The index of the first layer with the tag, or 0 if none have it.
+func layerWithTag(layers []GameMap, tag string) int {
+	for i, layer := range layers {
+		for _, t := range layer.Meta().Tags {
+			if t == tag {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+// Layers returns the maps that are combined, in the order they're applied.
+func (m *CompositeMap) Layers() []GameMap {
+	return append([]GameMap(nil), m.layers...)
+}
+
+func (m *CompositeMap) ID() string {
+	ids := make([]string, len(m.layers))
+	for i, layer := range m.layers {
+		ids[i] = layer.ID()
+	}
+	return strings.Join(ids, CompositeMapSeparator)
+}
+
+// Meta combines the metadata of the layers. The map supports the board sizes
+// and numbers of players that every layer supports, and has the parameters of
+// every layer. Where layers recommend different values for a setting, the
+// first layer's value is used.
+func (m *CompositeMap) Meta() Metadata {
+	var names, descriptions, authors []string
+	meta := Metadata{
+		MinPlayers: 0,
+		MaxPlayers: -1,
+		BoardSizes: AnySize(),
+		Tags:       []string{},
+	}
+	seenTags := map[string]bool{}
+	seenParams := map[string]bool{}
+	seenAuthors := map[string]bool{}
+	for _, layer := range m.layers {
+		layerMeta := layer.Meta()
+		names = append(names, layerMeta.Name)
+		descriptions = append(descriptions, layerMeta.Description)
+		if !seenAuthors[layerMeta.Author] {
+			seenAuthors[layerMeta.Author] = true
+			authors = append(authors, layerMeta.Author)
+		}
+		meta.Version += layerMeta.Version
+		if layerMeta.MinPlayers > meta.MinPlayers {
+			meta.MinPlayers = layerMeta.MinPlayers
+		}
+		if meta.MaxPlayers < 0 || layerMeta.MaxPlayers < meta.MaxPlayers {
+			meta.MaxPlayers = layerMeta.MaxPlayers
+		}
+		meta.BoardSizes = intersectSizes(meta.BoardSizes, layerMeta.BoardSizes)
+		for _, tag := range layerMeta.Tags {
+			if !seenTags[tag] {
+				seenTags[tag] = true
+				meta.Tags = append(meta.Tags, tag)
+			}
+		}
+		for _, p := range layerMeta.Parameters {
+			if !seenParams[p.Name] {
+				seenParams[p.Name] = true
+				meta.Parameters = append(meta.Parameters, p)
+			}
+		}
+		for key, value := range layerMeta.DefaultSettings {
+			if meta.DefaultSettings == nil {
+				meta.DefaultSettings = map[string]string{}
+			}
+			if _, ok := meta.DefaultSettings[key]; !ok {
+				meta.DefaultSettings[key] = value
+			}
+		}
+	}
+	meta.Name = strings.Join(names, " + ")
+	meta.Description = strings.Join(descriptions, " + ")
+	meta.Author = strings.Join(authors, ", ")
+	return meta
+}
+
+// The board sizes in both a and b.
+func intersectSizes(a, b sizes) sizes {
+	if a.IsUnlimited() {
+		return b
+	}
+	if b.IsUnlimited() {
+		return a
+	}
+	result := sizes{}
+	for _, size := range a {
+		if b.IsAllowable(size.Width, size.Height) {
+			result = append(result, size)
+		}
+	}
+	return result
+}
+
+func (m *CompositeMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return m.apply(initialBoardState, editor, func(layer GameMap, layerState *rules.BoardState, layerEditor Editor) error {
+		return layer.SetupBoard(layerState, settings, layerEditor)
+	})
+}
+
+func (m *CompositeMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return m.apply(lastBoardState, editor, func(layer GameMap, layerState *rules.BoardState, layerEditor Editor) error {
+		return layer.PreUpdateBoard(layerState, settings, layerEditor)
+	})
+}
+
+func (m *CompositeMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return m.apply(lastBoardState, editor, func(layer GameMap, layerState *rules.BoardState, layerEditor Editor) error {
+		return layer.PostUpdateBoard(layerState, settings, layerEditor)
+	})
+}
+
+// Apply each layer in turn. The editor is expected to start with the same
+// board as the previous board state, as it does when a map is used with the
+// PreUpdateBoard and PostUpdateBoard helpers.
+//
+// Each layer is given a copy of the previous board with only its own hazards,
+// and edits its own copy of the board. The parts of the board it owns are
+// then copied to the board being built, which is written to the editor once
+// every layer has been applied.
+func (m *CompositeMap) apply(previousBoardState *rules.BoardState, editor Editor, update func(layer GameMap, layerState *rules.BoardState, layerEditor Editor) error) error {
+	previousHazards, err := m.layerHazards(previousBoardState)
+	if err != nil {
+		return err
+	}
+
+	next := previousBoardState.Clone()
+	next.GameState = editor.GameState()
+	next.PointState = editor.PointState()
+	hazards := make([][]rules.Point, len(m.layers))
+	for i, layer := range m.layers {
+		layerState := previousBoardState.Clone()
+		layerState.Hazards = previousHazards[i]
+		layerNext := next.Clone()
+		layerNext.Hazards = append([]rules.Point(nil), previousHazards[i]...)
+		// Layers share the map state, since they can't get in each other's way by using different keys
+		layerNext.GameState = next.GameState
+		layerNext.PointState = next.PointState
+
+		if err := update(layer, layerState, NewBoardStateEditor(layerNext)); err != nil {
+			return fmt.Errorf("%s: %w", layer.ID(), err)
+		}
+
+		if i == m.snakesOwner {
+			next.Snakes = layerNext.Snakes
+		}
+		if i == m.foodOwner {
+			next.Food = layerNext.Food
+		}
+		hazards[i] = layerNext.Hazards
+	}
+
+	for _, snake := range next.Snakes {
+		editor.PlaceSnake(snake.ID, snake.Body, snake.Health)
+	}
+	editor.ClearFood()
+	for _, p := range next.Food {
+		editor.AddFood(p)
+	}
+	editor.ClearHazards()
+	for _, layerHazards := range hazards {
+		for _, p := range layerHazards {
+			editor.AddHazard(p)
+		}
+	}
+	stored, err := json.Marshal(hazards)
+	if err != nil {
+		return err
+	}
+	editor.GameState()[compositeHazardsKey] = string(stored)
+	return nil
+}
+
+// Get the hazards each layer placed, which are stored in the board's game
+// state. If they haven't been stored, all the hazards belong to the first
+// layer.
+func (m *CompositeMap) layerHazards(boardState *rules.BoardState) ([][]rules.Point, error) {
+	hazards := make([][]rules.Point, len(m.layers))
+	stored, ok := boardState.GameState[compositeHazardsKey]
+	if !ok {
+		hazards[0] = append([]rules.Point(nil), boardState.Hazards...)
+		return hazards, nil
+	}
+
+	if err := json.Unmarshal([]byte(stored), &hazards); err != nil {
+		return nil, fmt.Errorf("invalid composite map state: %w", err)
+	}
+	if len(hazards) != len(m.layers) {
+		return nil, fmt.Errorf("invalid composite map state: hazards for %d layers, expected %d", len(hazards), len(m.layers))
+	}
+	return hazards, nil
+}
+
+// Look up each layer of a composite map ID like "royale+healing_pools" in a registry, and combine them.
+func (registry MapRegistry) getCompositeMap(id string) (GameMap, error) {
+	ids := strings.Split(id, CompositeMapSeparator)
+	layers := make([]GameMap, 0, len(ids))
+	for _, layerID := range ids {
+		layer, err := registry.GetMap(layerID)
+		if err != nil {
+			if err == rules.ErrorMapNotFound {
+				return nil, fmt.Errorf("%w: %s", err, layerID)
+			}
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+	return NewCompositeMap(layers...)
+}
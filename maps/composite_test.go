@@ -0,0 +1,182 @@
+package maps_test
+
+import (
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+// A StubMap with metadata, to use as a layer.
+type layerMap struct {
+	maps.StubMap
+	tags       []string
+	minPlayers int
+	boardSizes []maps.Dimensions
+	parameters []maps.Parameter
+	settings   map[string]string
+	// Clears its hazards and places one on the current turn, instead of adding the stub's hazards
+	clearHazards bool
+}
+
+func (m layerMap) Meta() maps.Metadata {
+	meta := maps.Metadata{
+		Name:       m.Id,
+		Author:     "Test",
+		Version:    1,
+		MinPlayers: 1,
+		MaxPlayers: 8,
+		BoardSizes: maps.AnySize(),
+		Tags:       m.tags,
+
+		Parameters:      m.parameters,
+		DefaultSettings: m.settings,
+	}
+	if m.minPlayers > 0 {
+		meta.MinPlayers = m.minPlayers
+	}
+	if len(m.boardSizes) > 0 {
+		meta.BoardSizes = maps.FixedSizes(m.boardSizes[0], m.boardSizes[1:]...)
+	}
+	return meta
+}
+
+func (m layerMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	if !m.clearHazards {
+		return m.StubMap.PostUpdateBoard(lastBoardState, settings, editor)
+	}
+	editor.ClearHazards()
+	editor.AddHazard(rules.Point{X: lastBoardState.Turn + 1, Y: 0})
+	return nil
+}
+
+func TestCompositeMapOwnership(t *testing.T) {
+	first := layerMap{
+		StubMap: maps.StubMap{
+			Id:             "first",
+			SnakePositions: map[string]rules.Point{"1": {X: 1, Y: 1}, "2": {X: 2, Y: 2}},
+			Food:           []rules.Point{{X: 0, Y: 1}},
+			Hazards:        []rules.Point{{X: 0, Y: 0}},
+		},
+		clearHazards: true,
+	}
+	second := layerMap{
+		StubMap: maps.StubMap{
+			Id:             "second",
+			SnakePositions: map[string]rules.Point{"1": {X: 4, Y: 4}, "2": {X: 5, Y: 5}},
+			Food:           []rules.Point{{X: 3, Y: 3}},
+			Hazards:        []rules.Point{{X: 6, Y: 6}},
+		},
+		tags: []string{maps.TAG_FOOD_PLACEMENT},
+	}
+	m, err := maps.NewCompositeMap(first, second)
+	require.NoError(t, err)
+	require.Equal(t, "first+second", m.ID())
+
+	boardState := rules.NewBoardState(11, 11)
+	rules.InitializeSnakes(boardState, []string{"1", "2"})
+	require.NoError(t, m.SetupBoard(boardState, rules.Settings{}, maps.NewBoardStateEditor(boardState)))
+
+	// Snakes are placed by the first layer, since neither are tagged, and food by the second
+	require.Equal(t, []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}}, boardState.Snakes[0].Body)
+	require.Equal(t, []rules.Point{{X: 2, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 2}}, boardState.Snakes[1].Body)
+	require.Equal(t, []rules.Point{{X: 3, Y: 3}}, boardState.Food)
+	require.Equal(t, []rules.Point{{X: 0, Y: 0}, {X: 6, Y: 6}}, boardState.Hazards)
+
+	// The first layer clearing its hazards doesn't remove the second layer's
+	for turn := 0; turn < 3; turn++ {
+		boardState, err = maps.PostUpdateBoard(m, boardState, rules.Settings{})
+		require.NoError(t, err)
+		boardState.Turn++
+	}
+	require.Equal(t, []rules.Point{{X: 3, Y: 0}, {X: 6, Y: 6}, {X: 6, Y: 6}, {X: 6, Y: 6}, {X: 6, Y: 6}}, boardState.Hazards)
+	require.Equal(t, []rules.Point{{X: 3, Y: 3}, {X: 3, Y: 3}, {X: 3, Y: 3}, {X: 3, Y: 3}}, boardState.Food)
+
+	// Snake placement goes to the first tagged layer
+	second.tags = []string{maps.TAG_SNAKE_PLACEMENT}
+	m, err = maps.NewCompositeMap(first, second)
+	require.NoError(t, err)
+	boardState = rules.NewBoardState(11, 11)
+	rules.InitializeSnakes(boardState, []string{"1", "2"})
+	require.NoError(t, m.SetupBoard(boardState, rules.Settings{}, maps.NewBoardStateEditor(boardState)))
+	require.Equal(t, rules.Point{X: 4, Y: 4}, boardState.Snakes[0].Body[0])
+	require.Equal(t, []rules.Point{{X: 0, Y: 1}}, boardState.Food)
+}
+
+func TestCompositeMapMeta(t *testing.T) {
+	first := layerMap{StubMap: maps.StubMap{Id: "first"}, tags: []string{maps.TAG_HAZARD_PLACEMENT}, minPlayers: 2}
+	second := layerMap{
+		StubMap:    maps.StubMap{Id: "second"},
+		tags:       []string{maps.TAG_HAZARD_PLACEMENT, maps.TAG_FOOD_PLACEMENT},
+		boardSizes: []maps.Dimensions{{Width: 7, Height: 7}, {Width: 11, Height: 11}},
+	}
+	third := layerMap{StubMap: maps.StubMap{Id: "third"}, boardSizes: []maps.Dimensions{{Width: 11, Height: 11}, {Width: 19, Height: 19}}}
+
+	m, err := maps.NewCompositeMap(first, second, third)
+	require.NoError(t, err)
+	meta := m.Meta()
+	require.Equal(t, "first + second + third", meta.Name)
+	require.Equal(t, "Test", meta.Author)
+	require.Equal(t, 3, meta.Version)
+	require.Equal(t, 2, meta.MinPlayers)
+	require.Equal(t, 8, meta.MaxPlayers)
+	require.Equal(t, []maps.Dimensions{{Width: 11, Height: 11}}, []maps.Dimensions(meta.BoardSizes))
+	require.Equal(t, []string{maps.TAG_HAZARD_PLACEMENT, maps.TAG_FOOD_PLACEMENT}, meta.Tags)
+	require.Len(t, m.Layers(), 3)
+
+	_, err = maps.NewCompositeMap(first)
+	require.Error(t, err)
+	second.boardSizes = []maps.Dimensions{{Width: 7, Height: 7}}
+	_, err = maps.NewCompositeMap(second, third)
+	require.EqualError(t, err, "maps in second+third don't have any board sizes in common")
+	first.minPlayers = 9
+	_, err = maps.NewCompositeMap(first, third)
+	require.EqualError(t, err, "maps in first+third don't support any of the same numbers of players")
+}
+
+func TestCompositeMapParameters(t *testing.T) {
+	shared := maps.Parameter{Name: "shared.size", Type: maps.ParameterTypeInt, Default: "1"}
+	first := layerMap{
+		StubMap:    maps.StubMap{Id: "first"},
+		parameters: []maps.Parameter{shared},
+		settings:   map[string]string{rules.ParamHazardDamagePerTurn: "100"},
+	}
+	second := layerMap{
+		StubMap:    maps.StubMap{Id: "second"},
+		parameters: []maps.Parameter{{Name: "second.enabled", Type: maps.ParameterTypeBool, Default: "true"}, shared},
+		settings:   map[string]string{rules.ParamHazardDamagePerTurn: "50", rules.ParamShrinkEveryNTurns: "5"},
+	}
+
+	m, err := maps.NewCompositeMap(first, second)
+	require.NoError(t, err)
+	meta := m.Meta()
+	require.Equal(t, []maps.Parameter{shared, second.parameters[0]}, meta.Parameters)
+	require.Equal(t, map[string]string{rules.ParamHazardDamagePerTurn: "100", rules.ParamShrinkEveryNTurns: "5"}, meta.DefaultSettings)
+}
+
+func TestGetCompositeMap(t *testing.T) {
+	m, err := maps.GetMap("royale+healing_pools")
+	require.NoError(t, err)
+	require.Equal(t, "royale+healing_pools", m.ID())
+	require.IsType(t, &maps.CompositeMap{}, m)
+
+	_, err = maps.GetMap("royale+missing")
+	require.ErrorIs(t, err, rules.ErrorMapNotFound)
+
+	// Healing pools are kept when royale regenerates its hazards
+	settings := rules.NewSettingsWithParams(rules.ParamShrinkEveryNTurns, "5").WithSeed(1)
+	boardState := rules.NewBoardState(11, 11)
+	rules.InitializeSnakes(boardState, []string{"1", "2"})
+	require.NoError(t, m.SetupBoard(boardState, settings, maps.NewBoardStateEditor(boardState)))
+	require.Len(t, boardState.Hazards, 2)
+	pools := boardState.Hazards
+
+	for turn := 0; turn < 5; turn++ {
+		boardState, err = maps.PostUpdateBoard(m, boardState, settings)
+		require.NoError(t, err)
+		boardState.Turn++
+	}
+	require.Len(t, boardState.Hazards, 2+11)
+	require.Subset(t, boardState.Hazards, pools)
+}
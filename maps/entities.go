@@ -0,0 +1,315 @@
+package maps
+
+import (
+	"fmt"
+
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// EntityPath is the rule an entity follows to move each turn.
+type EntityPath string
+
+const (
+	// Moves by its velocity in a straight line, and turns back when it would
+	// leave the board or move into a wall declared with SetEntityWalls.
+	EntityPathBounce EntityPath = "bounce"
+	// Moves by its velocity in a straight line, wrapping around the edges of the board.
+	EntityPathWrap EntityPath = "wrap"
+	// Moves one cell at a time, choosing a random direction at junctions and
+	// avoiding walls. It only turns back at dead ends.
+	EntityPathWander EntityPath = "wander"
+	// Rotates its body a quarter turn clockwise around its head. It doesn't
+	// rotate if part of it would leave the board.
+	EntityPathRotate EntityPath = "rotate"
+)
+
+// An Entity is a hazard owned by a map that moves around the board, such as
+// a patrolling ghost or a rotating wall. Every cell of its body is a hazard,
+// so snakes take hazard damage when they move into it. Cells that are already
+// hazards aren't stacked, so the damage is the same as on any other hazard.
+//
+// Entities are placed with PlaceEntity, and are kept in the map state between
+// turns. The map helpers move every entity along its path after the ruleset
+// has run and before PostUpdateBoard is called, so a map sees its entities
+// in their new positions in PostUpdateBoard.
+//
+// The hazards for entities are added to the board by the map helpers once the
+// map has updated the board, and they aren't included in the board states
+// passed to maps. This keeps them separate from the hazards maps place
+// themselves, which some maps clear and rebuild every turn.
+type Entity struct {
+	ID string
+	// The cells the entity covers, starting with its head
+	Body []rules.Point
+	// Cells the entity moves each time it moves. For wandering entities,
+	// the direction it moved last.
+	Velocity rules.Point
+	Path     EntityPath
+	// Number of turns between each move. Entities with a period of 1 or less move every turn.
+	Period int
+}
+
+// The map state namespace the framework keeps entities in. Map IDs can't
+// contain the version separator, so it can't clash with a map's namespace.
+const entitiesNamespace = MapVersionSeparator + "entities"
+
+// Entities returns the entities on a board.
+func Entities(boardState *rules.BoardState) ([]Entity, error) {
+	return loadEntities(NewMapState(boardState, entitiesNamespace))
+}
+
+// EditorEntities returns the entities on the board an Editor changes.
+// Note: the return value is a copy and modifying it won't affect the board.
+func EditorEntities(editor Editor) ([]Entity, error) {
+	return loadEntities(editor.MapState(entitiesNamespace))
+}
+
+// PlaceEntity places an entity on the board an Editor changes, replacing any
+// entity with the same ID.
+func PlaceEntity(editor Editor, entity Entity) error {
+	state := editor.MapState(entitiesNamespace)
+	entities, err := loadEntities(state)
+	if err != nil {
+		return err
+	}
+	entity.Body = append([]rules.Point(nil), entity.Body...)
+	for i := range entities {
+		if entities[i].ID == entity.ID {
+			entities[i] = entity
+			return storeEntities(state, entities)
+		}
+	}
+	return storeEntities(state, append(entities, entity))
+}
+
+// SetEntityWalls declares the cells that entities can't move into on the
+// board an Editor changes, replacing any walls declared before. Other hazards
+// don't block entities, so a map whose hazards are all walls should declare
+// them all.
+func SetEntityWalls(editor Editor, walls []rules.Point) error {
+	state := editor.MapState(entitiesNamespace)
+	if len(walls) == 0 {
+		state.Delete("walls")
+		return nil
+	}
+	return state.Store("walls", walls)
+}
+
+// RemoveEntity removes an entity from the board an Editor changes.
+func RemoveEntity(editor Editor, id string) error {
+	state := editor.MapState(entitiesNamespace)
+	entities, err := loadEntities(state)
+	if err != nil {
+		return err
+	}
+	kept := entities[:0]
+	for _, entity := range entities {
+		if entity.ID != id {
+			kept = append(kept, entity)
+		}
+	}
+	return storeEntities(state, kept)
+}
+
+func loadEntities(state MapState) ([]Entity, error) {
+	var entities []Entity
+	if _, err := state.Load("entities", &entities); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func storeEntities(state MapState, entities []Entity) error {
+	if len(entities) == 0 {
+		state.Delete("entities")
+		return nil
+	}
+	return state.Store("entities", entities)
+}
+
+// Remove the hazards for the entities on a board, returning a copy of the
+// board with only the hazards placed by the map.
+func withoutEntityHazards(boardState *rules.BoardState) (*rules.BoardState, error) {
+	state := NewMapState(boardState, entitiesNamespace)
+	var added []rules.Point
+	found, err := state.Load("hazards", &added)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		// Boards whose entities weren't added by the map helpers, such as
+		// hand-written states, have a hazard under every entity cell
+		entities, err := loadEntities(state)
+		if err != nil {
+			return nil, err
+		}
+		seen := map[rules.Point]bool{}
+		for _, entity := range entities {
+			for _, p := range entity.Body {
+				if !seen[p] {
+					seen[p] = true
+					added = append(added, p)
+				}
+			}
+		}
+	}
+	result := boardState.Clone()
+	for _, p := range added {
+		result.Hazards = removeOnePoint(result.Hazards, p)
+	}
+	return result, nil
+}
+
+// Add a hazard for every cell of the entities on a board. Cells that already
+// have a hazard, from the map or another entity, don't get a second one, so
+// snakes never take more damage on an entity than on any other hazard. The
+// cells that were added are kept in the map state so that they can be removed
+// again.
+func addEntityHazards(boardState *rules.BoardState) error {
+	state := NewMapState(boardState, entitiesNamespace)
+	entities, err := loadEntities(state)
+	if err != nil {
+		return err
+	}
+	hazards := make(map[rules.Point]bool, len(boardState.Hazards))
+	for _, p := range boardState.Hazards {
+		hazards[p] = true
+	}
+	added := []rules.Point{}
+	for _, entity := range entities {
+		for _, p := range entity.Body {
+			if !hazards[p] {
+				hazards[p] = true
+				added = append(added, p)
+			}
+		}
+	}
+	boardState.Hazards = append(boardState.Hazards, added...)
+	if len(entities) == 0 {
+		state.Delete("hazards")
+		return nil
+	}
+	return state.Store("hazards", added)
+}
+
+// Remove a single point from a list of points, if it's there.
+func removeOnePoint(points []rules.Point, p rules.Point) []rules.Point {
+	for i, point := range points {
+		if point == p {
+			return append(points[:i:i], points[i+1:]...)
+		}
+	}
+	return points
+}
+
+// Move every entity on a board along its path, avoiding the walls declared
+// with SetEntityWalls.
+func advanceEntities(boardState *rules.BoardState, settings rules.Settings) error {
+	state := NewMapState(boardState, entitiesNamespace)
+	entities, err := loadEntities(state)
+	if len(entities) == 0 || err != nil {
+		return err
+	}
+
+	var declaredWalls []rules.Point
+	if _, err := state.Load("walls", &declaredWalls); err != nil {
+		return err
+	}
+	walls := make(map[rules.Point]bool, len(declaredWalls))
+	for _, p := range declaredWalls {
+		walls[rules.Point{X: p.X, Y: p.Y}] = true
+	}
+	rand := settings.GetRand(boardState.Turn)
+	for i := range entities {
+		entity := &entities[i]
+		if entity.Period > 1 && boardState.Turn%entity.Period != 0 {
+			continue
+		}
+		if err := entity.advance(boardState.Width, boardState.Height, walls, rand); err != nil {
+			return err
+		}
+	}
+	return storeEntities(state, entities)
+}
+
+// The directions a wandering entity can move in, in the order they're considered.
+var entityDirections = []rules.Point{{X: 0, Y: 1}, {X: 1, Y: 0}, {X: 0, Y: -1}, {X: -1, Y: 0}}
+
+func (entity *Entity) advance(width, height int, walls map[rules.Point]bool, rand rules.Rand) error {
+	// Whether the entity can move to a body without leaving the board or entering a wall
+	free := func(body []rules.Point) bool {
+		for _, p := range body {
+			if p.X < 0 || p.Y < 0 || p.X >= width || p.Y >= height || walls[p] {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch entity.Path {
+	case EntityPathBounce:
+		if moved := translate(entity.Body, entity.Velocity); free(moved) {
+			entity.Body = moved
+			return nil
+		}
+		entity.Velocity = rules.Point{X: -entity.Velocity.X, Y: -entity.Velocity.Y}
+		if moved := translate(entity.Body, entity.Velocity); free(moved) {
+			entity.Body = moved
+		}
+	case EntityPathWrap:
+		moved := translate(entity.Body, entity.Velocity)
+		for i, p := range moved {
+			moved[i] = rules.Point{X: ((p.X % width) + width) % width, Y: ((p.Y % height) + height) % height}
+		}
+		entity.Body = moved
+	case EntityPathWander:
+		back := rules.Point{X: -entity.Velocity.X, Y: -entity.Velocity.Y}
+		var forward []rules.Point
+		canTurnBack := false
+		for _, direction := range entityDirections {
+			if !free(translate(entity.Body, direction)) {
+				continue
+			}
+			if direction == back {
+				canTurnBack = true
+				continue
+			}
+			forward = append(forward, direction)
+		}
+		switch {
+		case len(forward) > 0:
+			entity.Velocity = forward[rand.Intn(len(forward))]
+		case canTurnBack:
+			entity.Velocity = back
+		default:
+			return nil
+		}
+		entity.Body = translate(entity.Body, entity.Velocity)
+	case EntityPathRotate:
+		if len(entity.Body) == 0 {
+			return nil
+		}
+		pivot := entity.Body[0]
+		rotated := make([]rules.Point, len(entity.Body))
+		for i, p := range entity.Body {
+			rotated[i] = rules.Point{X: pivot.X + (p.Y - pivot.Y), Y: pivot.Y - (p.X - pivot.X)}
+		}
+		for _, p := range rotated {
+			if p.X < 0 || p.Y < 0 || p.X >= width || p.Y >= height {
+				return nil
+			}
+		}
+		entity.Body = rotated
+	default:
+		return fmt.Errorf("entity %s has unknown path %#v", entity.ID, entity.Path)
+	}
+	return nil
+}
+
+func translate(body []rules.Point, by rules.Point) []rules.Point {
+	moved := make([]rules.Point, len(body))
+	for i, p := range body {
+		moved[i] = rules.Point{X: p.X + by.X, Y: p.Y + by.Y}
+	}
+	return moved
+}
@@ -0,0 +1,224 @@
+package maps_test
+
+import (
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+// Places walls and entities when it sets up the board, and records the
+// hazards and entities it's given in PostUpdateBoard.
+type entityMap struct {
+	maps.StubMap
+	entities []maps.Entity
+	// The hazards entities can't move into, which can be fewer than the map's hazards
+	walls []rules.Point
+	// Clears and re-adds its hazards each turn, like maps that rebuild their hazards
+	rebuildHazards bool
+
+	seenHazards  *[]rules.Point
+	seenEntities *[]maps.Entity
+}
+
+func (m entityMap) Meta() maps.Metadata {
+	return maps.Metadata{Name: m.Id, Version: 1, MaxPlayers: 8, BoardSizes: maps.AnySize(), Tags: []string{maps.TAG_HAZARD_PLACEMENT}}
+}
+
+func (m entityMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	for _, p := range m.Hazards {
+		editor.AddHazard(p)
+	}
+	for _, entity := range m.entities {
+		if err := maps.PlaceEntity(editor, entity); err != nil {
+			return err
+		}
+	}
+	return maps.SetEntityWalls(editor, m.walls)
+}
+
+func (m entityMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	return nil
+}
+
+func (m entityMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	if m.seenHazards != nil {
+		*m.seenHazards = append([]rules.Point(nil), lastBoardState.Hazards...)
+	}
+	if m.seenEntities != nil {
+		entities, err := maps.EditorEntities(editor)
+		if err != nil {
+			return err
+		}
+		*m.seenEntities = entities
+	}
+	if m.rebuildHazards {
+		editor.ClearHazards()
+		for _, p := range lastBoardState.Hazards {
+			editor.AddHazard(p)
+		}
+	}
+	return nil
+}
+
+// Set up a board with a map and play turns, returning the body of the first entity after each turn.
+func entityTurns(t *testing.T, gameMap maps.GameMap, width, height, turns int) ([]*rules.BoardState, [][]rules.Point) {
+	boardState, err := maps.SetupBoardWithMap(gameMap, rules.Settings{}.WithSeed(1), width, height, nil)
+	require.NoError(t, err)
+
+	var boardStates []*rules.BoardState
+	var bodies [][]rules.Point
+	for turn := 0; turn < turns; turn++ {
+		boardState.Turn = turn
+		boardState, err = maps.PreUpdateBoard(gameMap, boardState, rules.Settings{}.WithSeed(1))
+		require.NoError(t, err)
+		boardState, err = maps.PostUpdateBoard(gameMap, boardState, rules.Settings{}.WithSeed(1))
+		require.NoError(t, err)
+
+		entities, err := maps.Entities(boardState)
+		require.NoError(t, err)
+		boardStates = append(boardStates, boardState)
+		bodies = append(bodies, entities[0].Body)
+	}
+	return boardStates, bodies
+}
+
+func TestEntityPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		walls    []rules.Point
+		hazards  []rules.Point // hazards that aren't walls
+		entity   maps.Entity
+		expected [][]rules.Point
+	}{
+		{
+			name:   "bounce off edges",
+			entity: maps.Entity{Body: []rules.Point{{X: 1, Y: 0}}, Velocity: rules.Point{X: 1}, Path: maps.EntityPathBounce},
+			expected: [][]rules.Point{
+				{{X: 2, Y: 0}}, {{X: 1, Y: 0}}, {{X: 0, Y: 0}}, {{X: 1, Y: 0}},
+			},
+		},
+		{
+			name:   "bounce off walls",
+			walls:  []rules.Point{{X: 1, Y: 2}},
+			entity: maps.Entity{Body: []rules.Point{{X: 1, Y: 0}, {X: 2, Y: 0}}, Velocity: rules.Point{Y: 1}, Path: maps.EntityPathBounce},
+			expected: [][]rules.Point{
+				{{X: 1, Y: 1}, {X: 2, Y: 1}}, {{X: 1, Y: 0}, {X: 2, Y: 0}}, {{X: 1, Y: 1}, {X: 2, Y: 1}},
+			},
+		},
+		{
+			name:    "bounce through other hazards",
+			hazards: []rules.Point{{X: 1, Y: 2}},
+			entity:  maps.Entity{Body: []rules.Point{{X: 1, Y: 0}}, Velocity: rules.Point{Y: 1}, Path: maps.EntityPathBounce},
+			expected: [][]rules.Point{
+				{{X: 1, Y: 1}}, {{X: 1, Y: 2}}, {{X: 1, Y: 1}},
+			},
+		},
+		{
+			name:   "wrap",
+			entity: maps.Entity{Body: []rules.Point{{X: 1, Y: 1}}, Velocity: rules.Point{X: -1, Y: 2}, Path: maps.EntityPathWrap},
+			expected: [][]rules.Point{
+				{{X: 0, Y: 0}}, {{X: 2, Y: 2}}, {{X: 1, Y: 1}},
+			},
+		},
+		{
+			name: "wander along a corridor",
+			walls: []rules.Point{
+				{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1},
+				{X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2},
+			},
+			entity: maps.Entity{Body: []rules.Point{{X: 0, Y: 0}}, Path: maps.EntityPathWander},
+			expected: [][]rules.Point{
+				{{X: 1, Y: 0}}, {{X: 2, Y: 0}}, {{X: 1, Y: 0}}, {{X: 0, Y: 0}}, {{X: 1, Y: 0}},
+			},
+		},
+		{
+			name:   "rotate",
+			entity: maps.Entity{Body: []rules.Point{{X: 1, Y: 1}, {X: 2, Y: 1}}, Path: maps.EntityPathRotate},
+			expected: [][]rules.Point{
+				{{X: 1, Y: 1}, {X: 1, Y: 0}}, {{X: 1, Y: 1}, {X: 0, Y: 1}}, {{X: 1, Y: 1}, {X: 1, Y: 2}}, {{X: 1, Y: 1}, {X: 2, Y: 1}},
+			},
+		},
+		{
+			name:   "period",
+			entity: maps.Entity{Body: []rules.Point{{X: 0, Y: 0}}, Velocity: rules.Point{X: 1}, Path: maps.EntityPathWrap, Period: 2},
+			expected: [][]rules.Point{
+				{{X: 1, Y: 0}}, {{X: 1, Y: 0}}, {{X: 2, Y: 0}}, {{X: 2, Y: 0}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.entity.ID = "entity"
+			hazards := append(append([]rules.Point(nil), test.walls...), test.hazards...)
+			m := entityMap{StubMap: maps.StubMap{Id: "entities", Hazards: hazards}, entities: []maps.Entity{test.entity}, walls: test.walls}
+			_, bodies := entityTurns(t, m, 3, 3, len(test.expected))
+			require.Equal(t, test.expected, bodies)
+		})
+	}
+}
+
+func TestEntityHazards(t *testing.T) {
+	walls := []rules.Point{{X: 0, Y: 2}, {X: 1, Y: 1}}
+	entity := maps.Entity{ID: "entity", Body: []rules.Point{{X: 1, Y: 1}}, Velocity: rules.Point{X: 1}, Path: maps.EntityPathWrap}
+	var seenHazards []rules.Point
+	var seenEntities []maps.Entity
+	m := entityMap{
+		StubMap:        maps.StubMap{Id: "entities", Hazards: walls},
+		entities:       []maps.Entity{entity},
+		rebuildHazards: true,
+		seenHazards:    &seenHazards,
+		seenEntities:   &seenEntities,
+	}
+
+	// every cell of an entity is a hazard, but cells that are already hazards don't get a second one
+	boardState, err := maps.SetupBoardWithMap(m, rules.Settings{}, 3, 3, nil)
+	require.NoError(t, err)
+	require.Equal(t, []rules.Point{{X: 0, Y: 2}, {X: 1, Y: 1}}, boardState.Hazards)
+
+	// maps only see their own hazards, and see entities after they've moved
+	boardStates, _ := entityTurns(t, m, 3, 3, 2)
+	require.Equal(t, walls, seenHazards)
+	require.Equal(t, []rules.Point{{X: 0, Y: 1}}, seenEntities[0].Body)
+	require.Equal(t, []rules.Point{{X: 2, Y: 1}}, boardStates[0].Hazards[len(boardStates[0].Hazards)-1:])
+	require.Equal(t, []rules.Point{{X: 0, Y: 2}, {X: 1, Y: 1}, {X: 0, Y: 1}}, boardStates[1].Hazards)
+
+	// overlapping entities only add one hazard between them
+	m = entityMap{StubMap: maps.StubMap{Id: "entities"}, entities: []maps.Entity{
+		{ID: "one", Body: []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 2}}, Path: maps.EntityPathRotate},
+		{ID: "two", Body: []rules.Point{{X: 1, Y: 2}}, Path: maps.EntityPathRotate},
+	}}
+	boardState, err = maps.SetupBoardWithMap(m, rules.Settings{}, 3, 3, nil)
+	require.NoError(t, err)
+	require.Equal(t, []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 2}}, boardState.Hazards)
+}
+
+func TestEntityEditor(t *testing.T) {
+	boardState := rules.NewBoardState(7, 7)
+	editor := maps.NewBoardStateEditor(boardState)
+
+	require.NoError(t, maps.PlaceEntity(editor, maps.Entity{ID: "one", Body: []rules.Point{{X: 1, Y: 1}}, Path: maps.EntityPathWander}))
+	require.NoError(t, maps.PlaceEntity(editor, maps.Entity{ID: "two", Body: []rules.Point{{X: 2, Y: 2}}, Path: maps.EntityPathWander}))
+	require.NoError(t, maps.PlaceEntity(editor, maps.Entity{ID: "one", Body: []rules.Point{{X: 3, Y: 3}}, Path: maps.EntityPathRotate}))
+
+	entities, err := maps.EditorEntities(editor)
+	require.NoError(t, err)
+	require.Equal(t, []maps.Entity{
+		{ID: "one", Body: []rules.Point{{X: 3, Y: 3}}, Path: maps.EntityPathRotate},
+		{ID: "two", Body: []rules.Point{{X: 2, Y: 2}}, Path: maps.EntityPathWander},
+	}, entities)
+
+	require.NoError(t, maps.RemoveEntity(editor, "one"))
+	require.NoError(t, maps.RemoveEntity(editor, "two"))
+	entities, err = maps.EditorEntities(editor)
+	require.NoError(t, err)
+	require.Empty(t, entities)
+	require.Empty(t, boardState.GameState)
+
+	// entities with unknown paths can't be moved
+	require.NoError(t, maps.PlaceEntity(editor, maps.Entity{ID: "one", Body: []rules.Point{{X: 1, Y: 1}}, Path: "teleport"}))
+	_, err = maps.PostUpdateBoard(maps.StubMap{}, boardState, rules.Settings{})
+	require.EqualError(t, err, `entity one has unknown path "teleport"`)
+}
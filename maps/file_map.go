@@ -0,0 +1,387 @@
+package maps
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"gopkg.in/yaml.v3"
+)
+
+// Actions a HazardPattern can take on the hazards on the board.
+const (
+	HazardActionAdd     = "add"     // add the pattern's hazards to the board
+	HazardActionRemove  = "remove"  // remove every hazard, including stacked hazards, on the pattern's points
+	HazardActionReplace = "replace" // replace every hazard on the board with the pattern's hazards
+)
+
+// FileMap is a game map defined by a JSON or YAML file instead of Go code.
+//
+// Snakes are placed randomly on the start positions, or using the standard
+// placement when there aren't any. Food spawns using the standard rules, but
+// only in the food spawn zones when there are some.
+type FileMap struct {
+	MapID       string   `json:"id" yaml:"id"`
+	Name        string   `json:"name" yaml:"name"`
+	Author      string   `json:"author" yaml:"author"`
+	Description string   `json:"description" yaml:"description"`
+	Version     int      `json:"version" yaml:"version"`
+	MinPlayers  int      `json:"minPlayers" yaml:"minPlayers"`
+	MaxPlayers  int      `json:"maxPlayers" yaml:"maxPlayers"`
+	BoardSizes  sizes    `json:"boardSizes" yaml:"boardSizes"`
+	Tags        []string `json:"tags" yaml:"tags"`
+
+	StartPositions []rules.Point   `json:"startPositions" yaml:"startPositions"`
+	Hazards        []rules.Point   `json:"hazards" yaml:"hazards"`
+	Food           []rules.Point   `json:"food" yaml:"food"`
+	FoodSpawnZones []rules.Point   `json:"foodSpawnZones" yaml:"foodSpawnZones"`
+	HazardPatterns []HazardPattern `json:"hazardPatterns" yaml:"hazardPatterns"`
+
+	// The file the map was loaded from, if any.
+	Path string `json:"-" yaml:"-"`
+}
+
+// HazardPattern changes the hazards on the board on certain turns. Patterns
+// are applied at the end of a turn, in the order they're listed.
+type HazardPattern struct {
+	// The first turn the pattern is applied on.
+	StartTurn int `json:"startTurn" yaml:"startTurn"`
+	// How often the pattern is applied after the first turn. When 0, the pattern is only applied once.
+	EveryNTurns int `json:"everyNTurns" yaml:"everyNTurns"`
+	// The last turn the pattern can be applied on. When 0, the pattern keeps being applied.
+	EndTurn int `json:"endTurn" yaml:"endTurn"`
+	// One of "add", "remove" or "replace". Defaults to "add".
+	Action string        `json:"action" yaml:"action"`
+	Points []rules.Point `json:"points" yaml:"points"`
+}
+
+// AppliesOnTurn reports whether the pattern should be applied at the end of a turn.
+func (pattern HazardPattern) AppliesOnTurn(turn int) bool {
+	if turn < pattern.StartTurn || (pattern.EndTurn > 0 && turn > pattern.EndTurn) {
+		return false
+	}
+	if pattern.EveryNTurns <= 0 {
+		return turn == pattern.StartTurn
+	}
+	return (turn-pattern.StartTurn)%pattern.EveryNTurns == 0
+}
+
+// LoadFileMap reads a map from a JSON or YAML file, depending on its extension.
+// When the map doesn't have an ID, it's named after the file.
+func LoadFileMap(path string) (*FileMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &FileMap{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, m)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, m)
+	default:
+		return nil, fmt.Errorf("%s: map files must be .json, .yaml or .yml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	m.Path = path
+	if m.MapID == "" {
+		m.MapID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if m.Version == 0 {
+		m.Version = 1
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Validate checks that the map can be played: that it has board sizes, and
+// that every point is on each of them.
+func (m *FileMap) Validate() error {
+	if m.MapID == "" {
+		return fmt.Errorf("map has no ID")
+	}
+	// These would make the ID look like a composite or versioned map ID
+	if strings.Contains(m.MapID, CompositeMapSeparator) || strings.Contains(m.MapID, MapVersionSeparator) {
+		return fmt.Errorf("map ID '%s' can't contain '%s' or '%s'", m.MapID, CompositeMapSeparator, MapVersionSeparator)
+	}
+	if len(m.BoardSizes) == 0 {
+		return fmt.Errorf("map has no board sizes")
+	}
+	for _, size := range m.BoardSizes {
+		if size.Width < 0 || size.Height < 0 || (size.Width == 0) != (size.Height == 0) {
+			return fmt.Errorf("invalid board size %dx%d", size.Width, size.Height)
+		}
+	}
+	if m.MinPlayers < 0 || (m.MaxPlayers > 0 && m.MinPlayers > m.MaxPlayers) {
+		return fmt.Errorf("invalid number of players %d-%d", m.MinPlayers, m.MaxPlayers)
+	}
+	if len(m.StartPositions) > 0 && m.MaxPlayers > len(m.StartPositions) {
+		return fmt.Errorf("map supports %d players, but only has %d start positions", m.MaxPlayers, len(m.StartPositions))
+	}
+
+	seen := map[rules.Point]bool{}
+	for _, p := range m.StartPositions {
+		if seen[p] {
+			return fmt.Errorf("start position (%d,%d) is listed more than once", p.X, p.Y)
+		}
+		seen[p] = true
+	}
+
+	if err := m.validatePoints("start position", m.StartPositions); err != nil {
+		return err
+	}
+	if err := m.validatePoints("hazard", m.Hazards); err != nil {
+		return err
+	}
+	if err := m.validatePoints("food", m.Food); err != nil {
+		return err
+	}
+	if err := m.validatePoints("food spawn zone", m.FoodSpawnZones); err != nil {
+		return err
+	}
+	for i, pattern := range m.HazardPatterns {
+		switch pattern.Action {
+		case "", HazardActionAdd, HazardActionRemove, HazardActionReplace:
+		default:
+			return fmt.Errorf("hazard pattern %d has unknown action %#v", i+1, pattern.Action)
+		}
+		if pattern.StartTurn < 0 || pattern.EveryNTurns < 0 || pattern.EndTurn < 0 {
+			return fmt.Errorf("hazard pattern %d has a negative turn", i+1)
+		}
+		if pattern.EndTurn > 0 && pattern.EndTurn < pattern.StartTurn {
+			return fmt.Errorf("hazard pattern %d ends before it starts", i+1)
+		}
+		if err := m.validatePoints(fmt.Sprintf("hazard pattern %d point", i+1), pattern.Points); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check that each point is on every board size the map supports.
+func (m *FileMap) validatePoints(kind string, points []rules.Point) error {
+	for _, p := range points {
+		if p.X < 0 || p.Y < 0 {
+			return fmt.Errorf("%s (%d,%d) is off the board", kind, p.X, p.Y)
+		}
+		if m.BoardSizes.IsUnlimited() {
+			continue
+		}
+		for _, size := range m.BoardSizes {
+			if p.X >= size.Width || p.Y >= size.Height {
+				return fmt.Errorf("%s (%d,%d) is off the %dx%d board", kind, p.X, p.Y, size.Width, size.Height)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *FileMap) ID() string {
+	return m.MapID
+}
+
+func (m *FileMap) Meta() Metadata {
+	maxPlayers := m.MaxPlayers
+	if maxPlayers == 0 {
+		maxPlayers = len(m.StartPositions)
+	}
+	if maxPlayers == 0 {
+		maxPlayers = StandardMap{}.Meta().MaxPlayers
+	}
+
+	tags := append([]string{}, m.Tags...)
+	if len(m.StartPositions) > 0 {
+		tags = appendTag(tags, TAG_SNAKE_PLACEMENT)
+	}
+	if len(m.Hazards) > 0 || len(m.HazardPatterns) > 0 {
+		tags = appendTag(tags, TAG_HAZARD_PLACEMENT)
+	}
+	if len(m.Food) > 0 || len(m.FoodSpawnZones) > 0 {
+		tags = appendTag(tags, TAG_FOOD_PLACEMENT)
+	}
+
+	return Metadata{
+		Name:        m.Name,
+		Author:      m.Author,
+		Description: m.Description,
+		Version:     m.Version,
+		MinPlayers:  m.MinPlayers,
+		MaxPlayers:  maxPlayers,
+		BoardSizes:  m.BoardSizes,
+		Tags:        tags,
+	}
+}
+
+func appendTag(tags []string, tag string) []string {
+	for _, t := range tags {
+		if t == tag {
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
+
+func (m *FileMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if err := m.Meta().Validate(initialBoardState); err != nil {
+		return err
+	}
+	rand := settings.GetRand(0)
+
+	if len(m.StartPositions) > 0 {
+		heads := append([]rules.Point{}, m.StartPositions...)
+		if err := editor.PlaceSnakesRandomlyAtPositions(rand, initialBoardState.Snakes, heads, rules.SnakeStartSize); err != nil {
+			return err
+		}
+	} else {
+		snakeIDs := make([]string, 0, len(initialBoardState.Snakes))
+		for _, snake := range initialBoardState.Snakes {
+			snakeIDs = append(snakeIDs, snake.ID)
+		}
+		tempBoardState := rules.NewBoardState(initialBoardState.Width, initialBoardState.Height)
+		if err := rules.PlaceSnakesAutomatically(rand, tempBoardState, snakeIDs); err != nil {
+			return err
+		}
+		for _, snake := range tempBoardState.Snakes {
+			editor.PlaceSnake(snake.ID, snake.Body, snake.Health)
+		}
+	}
+
+	for _, hazard := range m.Hazards {
+		editor.AddHazard(hazard)
+	}
+	for _, food := range m.Food {
+		editor.AddFood(food)
+	}
+	return nil
+}
+
+func (m *FileMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return nil
+}
+
+func (m *FileMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if len(m.FoodSpawnZones) == 0 {
+		if err := (StandardMap{}).PostUpdateBoard(lastBoardState, settings, editor); err != nil {
+			return err
+		}
+	} else {
+		rand := settings.GetRand(lastBoardState.Turn)
+		foodNeeded := checkFoodNeedingPlacement(rand, settings, lastBoardState)
+		if foodNeeded > 0 {
+			zones := editor.FilterUnoccupiedPoints(append([]rules.Point{}, m.FoodSpawnZones...), true, false, true)
+			placeFoodRandomlyAtPositions(rand, lastBoardState, editor, foodNeeded, zones)
+		}
+	}
+
+	for _, pattern := range m.HazardPatterns {
+		if !pattern.AppliesOnTurn(lastBoardState.Turn) {
+			continue
+		}
+		switch pattern.Action {
+		case HazardActionRemove:
+			for _, p := range pattern.Points {
+				RemoveAllHazards(editor, p)
+			}
+		case HazardActionReplace:
+			editor.ClearHazards()
+			for _, p := range pattern.Points {
+				editor.AddHazard(p)
+			}
+		default:
+			for _, p := range pattern.Points {
+				editor.AddHazard(p)
+			}
+		}
+	}
+	return nil
+}
+
+// RegisterFileMap adds a map loaded from a file to the registry. Unlike
+// RegisterMap, it returns an error instead of panicking when the ID is
+// already taken or the map isn't valid. A map loaded from a file can replace
+// another one loaded from a file, so that changes can be reloaded.
+func (registry MapRegistry) RegisterFileMap(m *FileMap) error {
+	if err := registry.checkFileMap(m); err != nil {
+		return err
+	}
+	registry[m.ID()] = m
+	return nil
+}
+
+// Check that a map loaded from a file is valid and can be registered.
+func (registry MapRegistry) checkFileMap(m *FileMap) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	if existing, ok := registry[m.ID()]; ok {
+		if _, isFileMap := existing.(*FileMap); !isFileMap {
+			return fmt.Errorf("map '%s' is already a built-in map", m.ID())
+		}
+	}
+	return nil
+}
+
+// LoadDir loads every JSON and YAML map file in a directory into the
+// registry, and returns the IDs of the maps loaded. Every file is checked
+// before any are registered, so nothing is loaded if one of them is invalid.
+func (registry MapRegistry) LoadDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := map[string]string{}
+	var fileMaps []*FileMap
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".json", ".yaml", ".yml":
+		default:
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		m, err := LoadFileMap(path)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := loaded[m.ID()]; ok {
+			return nil, fmt.Errorf("%s: map '%s' is also defined in %s", path, m.ID(), other)
+		}
+		if err := registry.checkFileMap(m); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		loaded[m.ID()] = path
+		fileMaps = append(fileMaps, m)
+	}
+
+	ids := make([]string, 0, len(fileMaps))
+	for _, m := range fileMaps {
+		registry[m.ID()] = m
+		ids = append(ids, m.ID())
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// RegisterFileMap adds a map loaded from a file to the global registry.
+func RegisterFileMap(m *FileMap) error {
+	return globalRegistry.RegisterFileMap(m)
+}
+
+// LoadDir loads every map file in a directory into the global registry.
+func LoadDir(dir string) ([]string, error) {
+	return globalRegistry.LoadDir(dir)
+}
@@ -0,0 +1,196 @@
+package maps_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMapInterface(t *testing.T) {
+	var _ maps.GameMap = &maps.FileMap{}
+}
+
+func TestLoadFileMap(t *testing.T) {
+	jsonMap, err := maps.LoadFileMap(filepath.Join("testdata", "corners.json"))
+	require.NoError(t, err)
+	yamlMap, err := maps.LoadFileMap(filepath.Join("testdata", "corners.yaml"))
+	require.NoError(t, err)
+
+	require.Equal(t, "corners", jsonMap.ID())
+	require.Len(t, jsonMap.StartPositions, 4)
+	require.Equal(t, rules.Point{X: 5, Y: 1}, jsonMap.StartPositions[1])
+	require.Equal(t, maps.HazardActionReplace, jsonMap.HazardPatterns[1].Action)
+
+	// the same map in either format
+	yamlMap.Path = jsonMap.Path
+	require.Equal(t, jsonMap, yamlMap)
+
+	meta := jsonMap.Meta()
+	require.Equal(t, "Corners", meta.Name)
+	require.Equal(t, 4, meta.MaxPlayers)
+	require.True(t, meta.BoardSizes.IsAllowable(7, 7))
+	require.False(t, meta.BoardSizes.IsAllowable(11, 11))
+	require.ElementsMatch(t, []string{maps.TAG_SNAKE_PLACEMENT, maps.TAG_HAZARD_PLACEMENT, maps.TAG_FOOD_PLACEMENT}, meta.Tags)
+}
+
+func TestLoadFileMapDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plain.yml")
+	require.NoError(t, os.WriteFile(path, []byte("boardSizes: [{width: 0, height: 0}]\n"), 0644))
+
+	m, err := maps.LoadFileMap(path)
+	require.NoError(t, err)
+	require.Equal(t, "plain", m.ID())
+	require.Equal(t, 1, m.Meta().Version)
+	require.Equal(t, maps.StandardMap{}.Meta().MaxPlayers, m.Meta().MaxPlayers)
+	require.Empty(t, m.Meta().Tags)
+
+	// snakes are placed like the standard map when there are no start positions
+	boardState := rules.NewBoardState(11, 11)
+	rules.InitializeSnakes(boardState, []string{"1", "2"})
+	require.NoError(t, m.SetupBoard(boardState, rules.Settings{}, maps.NewBoardStateEditor(boardState)))
+	require.Len(t, boardState.Snakes, 2)
+	require.Len(t, boardState.Snakes[0].Body, rules.SnakeStartSize)
+}
+
+func TestLoadFileMapErrors(t *testing.T) {
+	tests := map[string]string{
+		"bad.txt":        `{}`,
+		"invalid.json":   `{"boardSizes": [`,
+		"nosizes.json":   `{}`,
+		"offboard.json":  `{"boardSizes": [{"width": 7, "height": 7}], "hazards": [{"x": 7, "y": 0}]}`,
+		"players.json":   `{"boardSizes": [{"width": 7, "height": 7}], "maxPlayers": 2, "startPositions": [{"x": 1, "y": 1}]}`,
+		"duplicate.json": `{"boardSizes": [{"width": 7, "height": 7}], "startPositions": [{"x": 1, "y": 1}, {"x": 1, "y": 1}]}`,
+		"action.json":    `{"boardSizes": [{"width": 7, "height": 7}], "hazardPatterns": [{"action": "explode"}]}`,
+		"turns.json":     `{"boardSizes": [{"width": 7, "height": 7}], "hazardPatterns": [{"startTurn": 5, "endTurn": 2}]}`,
+		"composite.json": `{"id": "royale+mine", "boardSizes": [{"width": 7, "height": 7}]}`,
+		"version@2.json": `{"boardSizes": [{"width": 7, "height": 7}]}`,
+	}
+	dir := t.TempDir()
+	for name, contents := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+			_, err := maps.LoadFileMap(path)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestFileMapSetupBoard(t *testing.T) {
+	m, err := maps.LoadFileMap(filepath.Join("testdata", "corners.json"))
+	require.NoError(t, err)
+
+	boardState := rules.NewBoardState(7, 7)
+	rules.InitializeSnakes(boardState, []string{"1", "2", "3"})
+	settings := rules.NewSettingsWithParams(rules.ParamMinimumFood, "1").WithSeed(42)
+	require.NoError(t, m.SetupBoard(boardState, settings, maps.NewBoardStateEditor(boardState)))
+
+	for _, snake := range boardState.Snakes {
+		require.Contains(t, m.StartPositions, snake.Body[0])
+		require.Len(t, snake.Body, rules.SnakeStartSize)
+	}
+	require.Equal(t, m.Hazards, boardState.Hazards)
+	require.Equal(t, m.Food, boardState.Food)
+
+	boardState = rules.NewBoardState(11, 11)
+	rules.InitializeSnakes(boardState, []string{"1"})
+	require.Error(t, m.SetupBoard(boardState, settings, maps.NewBoardStateEditor(boardState)))
+
+	boardState = rules.NewBoardState(7, 7)
+	rules.InitializeSnakes(boardState, []string{"1", "2", "3", "4", "5"})
+	require.Error(t, m.SetupBoard(boardState, settings, maps.NewBoardStateEditor(boardState)))
+}
+
+func TestFileMapPostUpdateBoard(t *testing.T) {
+	m, err := maps.LoadFileMap(filepath.Join("testdata", "corners.json"))
+	require.NoError(t, err)
+	settings := rules.NewSettingsWithParams(rules.ParamMinimumFood, "2", rules.ParamFoodSpawnChance, "0").WithSeed(1)
+
+	// food only spawns in the zones
+	boardState := rules.NewBoardState(7, 7)
+	next, err := maps.PostUpdateBoard(m, boardState, settings)
+	require.NoError(t, err)
+	require.ElementsMatch(t, m.FoodSpawnZones, next.Food)
+
+	hazardsAfterTurn := func(turn int, hazards []rules.Point) []rules.Point {
+		boardState := rules.NewBoardState(7, 7)
+		boardState.Turn = turn
+		boardState.Hazards = hazards
+		next, err := maps.PostUpdateBoard(m, boardState, settings)
+		require.NoError(t, err)
+		return next.Hazards
+	}
+	middle := []rules.Point{{X: 3, Y: 3}}
+	require.Equal(t, middle, hazardsAfterTurn(9, middle))
+	require.Equal(t, []rules.Point{{X: 3, Y: 3}, {X: 2, Y: 3}, {X: 4, Y: 3}}, hazardsAfterTurn(10, middle))
+	require.Equal(t, middle, hazardsAfterTurn(20, []rules.Point{{X: 3, Y: 3}, {X: 2, Y: 3}, {X: 4, Y: 3}}))
+	require.Equal(t, []rules.Point{{X: 3, Y: 3}, {X: 2, Y: 3}, {X: 4, Y: 3}}, hazardsAfterTurn(30, middle))
+}
+
+func TestHazardPatternAppliesOnTurn(t *testing.T) {
+	once := maps.HazardPattern{StartTurn: 5}
+	require.False(t, once.AppliesOnTurn(4))
+	require.True(t, once.AppliesOnTurn(5))
+	require.False(t, once.AppliesOnTurn(10))
+
+	repeating := maps.HazardPattern{StartTurn: 5, EveryNTurns: 3, EndTurn: 11}
+	require.True(t, repeating.AppliesOnTurn(5))
+	require.False(t, repeating.AppliesOnTurn(6))
+	require.True(t, repeating.AppliesOnTurn(8))
+	require.True(t, repeating.AppliesOnTurn(11))
+	require.False(t, repeating.AppliesOnTurn(14))
+
+	remove := maps.HazardPattern{Action: maps.HazardActionRemove, Points: []rules.Point{{X: 1, Y: 1}}}
+	m := &maps.FileMap{MapID: "remove", BoardSizes: maps.AnySize(), HazardPatterns: []maps.HazardPattern{remove}}
+	boardState := rules.NewBoardState(7, 7)
+	boardState.Hazards = []rules.Point{{X: 1, Y: 1}, {X: 2, Y: 2}, {X: 1, Y: 1}}
+	next, err := maps.PostUpdateBoard(m, boardState, rules.Settings{})
+	require.NoError(t, err)
+	require.Equal(t, []rules.Point{{X: 2, Y: 2}}, next.Hazards)
+}
+
+func TestMapRegistryLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "one.json"), []byte(`{"boardSizes": [{"width": 7, "height": 7}]}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "two.yaml"), []byte("boardSizes: [{width: 11, height: 11}]\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a map"), 0644))
+
+	registry := maps.MapRegistry{}
+	registry.RegisterMap("standard", maps.StandardMap{})
+	ids, err := registry.LoadDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"one", "two"}, ids)
+	require.Equal(t, []string{"one", "standard", "two"}, registry.List())
+
+	// loading again replaces the maps loaded from files
+	_, err = registry.LoadDir(dir)
+	require.NoError(t, err)
+
+	// but not built-in maps, or another file with the same ID
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "three.json"), []byte(`{"id": "standard", "boardSizes": [{"width": 7, "height": 7}]}`), 0644))
+	_, err = registry.LoadDir(dir)
+	require.ErrorContains(t, err, "already a built-in map")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "three.json"), []byte(`{"id": "one", "boardSizes": [{"width": 7, "height": 7}]}`), 0644))
+	_, err = registry.LoadDir(dir)
+	require.ErrorContains(t, err, "also defined in")
+
+	// nothing is loaded when one of the files is invalid
+	dir = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"boardSizes": [{"width": 7, "height": 7}]}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"id": "standard", "boardSizes": [{"width": 7, "height": 7}]}`), 0644))
+	_, err = registry.LoadDir(dir)
+	require.ErrorContains(t, err, "already a built-in map")
+	require.Equal(t, []string{"one", "standard", "two"}, registry.List())
+}
+
+func TestRegisterFileMapInvalidID(t *testing.T) {
+	registry := maps.MapRegistry{}
+	err := registry.RegisterFileMap(&maps.FileMap{MapID: "royale+mine", BoardSizes: maps.FixedSizes(maps.Dimensions{Width: 7, Height: 7})})
+	require.EqualError(t, err, "map ID 'royale+mine' can't contain '+' or '@'")
+	require.Empty(t, registry.List())
+}
@@ -59,6 +59,11 @@ type Metadata struct {
 	BoardSizes sizes
 	// Tags is a list of strings use to categorize the map.
 	Tags []string
+	// Parameters are the settings the map reads to change how it behaves.
+	Parameters []Parameter
+	// DefaultSettings are the ruleset settings recommended for the map, keyed by
+	// setting name. They're used for any settings that aren't chosen when a game is created.
+	DefaultSettings map[string]string
 }
 
 func (meta Metadata) Validate(boardState *rules.BoardState) error {
@@ -185,6 +190,10 @@ type Editor interface {
 	// Get an editable reference to the BoardState's PointState field
 	PointState() map[rules.Point]int
 
+	// Get the typed state kept between turns under a namespace, usually the
+	// map's ID. See MapState for when stored values are visible.
+	MapState(namespace string) MapState
+
 	// Given a list of Snakes and a list of head coordinates, randomly place
 	// the snakes on those coordinates, or return an error if placement of all
 	// Snakes is impossible.
@@ -299,6 +308,12 @@ func (editor *BoardStateEditor) PointState() map[rules.Point]int {
 	return editor.boardState.PointState
 }
 
+// Get the typed state kept between turns under a namespace, stored in the
+// BoardState's GameState field
+func (editor *BoardStateEditor) MapState(namespace string) MapState {
+	return NewMapState(editor.boardState, namespace)
+}
+
 // Given a list of Snakes and a list of head coordinates, randomly place
 // the snakes on those coordinates, or return an error if placement of all
 // Snakes is impossible.
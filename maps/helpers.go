@@ -6,47 +6,78 @@ import (
 
 // SetupBoard is a shortcut for looking up a map by ID and initializing a new board state with it.
 func SetupBoard(mapID string, settings rules.Settings, width, height int, snakeIDs []string) (*rules.BoardState, error) {
-	boardState := rules.NewBoardState(width, height)
-
-	rules.InitializeSnakes(boardState, snakeIDs)
-
 	gameMap, err := GetMap(mapID)
 	if err != nil {
 		return nil, err
 	}
 
+	return SetupBoardWithMap(gameMap, settings, width, height, snakeIDs)
+}
+
+// SetupBoardWithMap initializes a new board state with a map.
+func SetupBoardWithMap(gameMap GameMap, settings rules.Settings, width, height int, snakeIDs []string) (*rules.BoardState, error) {
+	boardState := rules.NewBoardState(width, height)
+
+	rules.InitializeSnakes(boardState, snakeIDs)
+
 	editor := NewBoardStateEditor(boardState)
 
-	err = gameMap.SetupBoard(boardState, settings, editor)
+	err := gameMap.SetupBoard(boardState, settings, editor)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := addEntityHazards(boardState); err != nil {
+		return nil, err
+	}
+
 	return boardState, nil
 }
 
 // PreUpdateBoard updates a board state with a map.
 func PreUpdateBoard(gameMap GameMap, previousBoardState *rules.BoardState, settings rules.Settings) (*rules.BoardState, error) {
+	previousBoardState, err := withoutEntityHazards(previousBoardState)
+	if err != nil {
+		return nil, err
+	}
+
 	nextBoardState := previousBoardState.Clone()
 	editor := NewBoardStateEditor(nextBoardState)
 
-	err := gameMap.PreUpdateBoard(previousBoardState, settings, editor)
+	err = gameMap.PreUpdateBoard(previousBoardState, settings, editor)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := addEntityHazards(nextBoardState); err != nil {
+		return nil, err
+	}
+
 	return nextBoardState, nil
 }
 
+// PostUpdateBoard moves the entities on a board, then updates it with a map.
 func PostUpdateBoard(gameMap GameMap, previousBoardState *rules.BoardState, settings rules.Settings) (*rules.BoardState, error) {
+	previousBoardState, err := withoutEntityHazards(previousBoardState)
+	if err != nil {
+		return nil, err
+	}
+	if err := advanceEntities(previousBoardState, settings); err != nil {
+		return nil, err
+	}
+
 	nextBoardState := previousBoardState.Clone()
 	editor := NewBoardStateEditor(nextBoardState)
 
-	err := gameMap.PostUpdateBoard(previousBoardState, settings, editor)
+	err = gameMap.PostUpdateBoard(previousBoardState, settings, editor)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := addEntityHazards(nextBoardState); err != nil {
+		return nil, err
+	}
+
 	return nextBoardState, nil
 }
 
@@ -297,3 +328,17 @@ func PlaceFoodFixed(rand rules.Rand, initialBoardState *rules.BoardState, editor
 
 	return nil
 }
+
+// RemoveAllHazards removes every hazard on a point, including stacked hazards,
+// and keeps the rest of the hazards in order. Editor.RemoveHazard moves the
+// last hazard into the place of each one it removes, so it doesn't keep the
+// order and doesn't always remove every stacked hazard.
+func RemoveAllHazards(editor Editor, p rules.Point) {
+	hazards := editor.Hazards()
+	editor.ClearHazards()
+	for _, hazard := range hazards {
+		if hazard != p {
+			editor.AddHazard(hazard)
+		}
+	}
+}
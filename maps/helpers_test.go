@@ -142,3 +142,12 @@ func TestPlaceFoodFixed(t *testing.T) {
 	require.Contains(t, food, rules.Point{X: 3, Y: 10})
 	require.Contains(t, food, rules.Point{X: 7, Y: 7})
 }
+
+func TestRemoveAllHazards(t *testing.T) {
+	boardState := rules.NewBoardState(11, 11).
+		WithHazards([]rules.Point{{X: 1, Y: 1}, {X: 1, Y: 1}, {X: 3, Y: 3}, {X: 2, Y: 2}, {X: 1, Y: 1}})
+	editor := maps.NewBoardStateEditor(boardState)
+
+	maps.RemoveAllHazards(editor, rules.Point{X: 1, Y: 1})
+	require.Equal(t, []rules.Point{{X: 3, Y: 3}, {X: 2, Y: 2}}, boardState.Hazards)
+}
@@ -0,0 +1,79 @@
+package maps
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// ParameterType is the type of value a map parameter takes.
+type ParameterType string
+
+const (
+	ParameterTypeInt  ParameterType = "int"
+	ParameterTypeBool ParameterType = "bool"
+)
+
+// Parameter describes a setting that changes how a map behaves. Parameters are
+// passed to maps in the game settings, under the parameter's name.
+type Parameter struct {
+	// Name is the settings key for the parameter. It should be prefixed with
+	// the map ID, so it doesn't clash with the parameters of other maps.
+	Name        string
+	Type        ParameterType
+	Default     string
+	Description string
+}
+
+// Validate checks that a value can be used for the parameter.
+func (p Parameter) Validate(value string) error {
+	switch p.Type {
+	case ParameterTypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("parameter %s must be an integer, got %#v", p.Name, value)
+		}
+	case ParameterTypeBool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("parameter %s must be true or false, got %#v", p.Name, value)
+		}
+	default:
+		return fmt.Errorf("parameter %s has unknown type %#v", p.Name, p.Type)
+	}
+	return nil
+}
+
+// Int returns the value of an int parameter from the settings, or its default.
+func (p Parameter) Int(settings rules.Settings) int {
+	defaultValue, _ := strconv.Atoi(p.Default)
+	return settings.Int(p.Name, defaultValue)
+}
+
+// Bool returns the value of a bool parameter from the settings, or its default.
+func (p Parameter) Bool(settings rules.Settings) bool {
+	return settings.Bool(p.Name, p.Default == "true")
+}
+
+// Parameter returns the map parameter with the given name.
+func (meta Metadata) Parameter(name string) (Parameter, bool) {
+	for _, p := range meta.Parameters {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Parameter{}, false
+}
+
+// ValidateParams checks that every parameter is declared by the map, and has a valid value.
+func (meta Metadata) ValidateParams(params map[string]string) error {
+	for name, value := range params {
+		p, ok := meta.Parameter(name)
+		if !ok {
+			return fmt.Errorf("map %s doesn't have a parameter named %s", meta.Name, name)
+		}
+		if err := p.Validate(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
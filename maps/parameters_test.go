@@ -0,0 +1,48 @@
+package maps_test
+
+import (
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParameterValidate(t *testing.T) {
+	intParam := maps.Parameter{Name: "test.count", Type: maps.ParameterTypeInt, Default: "3"}
+	require.NoError(t, intParam.Validate("-2"))
+	require.EqualError(t, intParam.Validate("three"), `parameter test.count must be an integer, got "three"`)
+
+	boolParam := maps.Parameter{Name: "test.enabled", Type: maps.ParameterTypeBool, Default: "false"}
+	require.NoError(t, boolParam.Validate("true"))
+	require.EqualError(t, boolParam.Validate("yes"), `parameter test.enabled must be true or false, got "yes"`)
+}
+
+func TestParameterValues(t *testing.T) {
+	intParam := maps.Parameter{Name: "test.count", Type: maps.ParameterTypeInt, Default: "3"}
+	boolParam := maps.Parameter{Name: "test.enabled", Type: maps.ParameterTypeBool, Default: "true"}
+
+	require.Equal(t, 3, intParam.Int(rules.Settings{}))
+	require.True(t, boolParam.Bool(rules.Settings{}))
+
+	settings := rules.NewSettingsWithParams("test.count", "7", "test.enabled", "false")
+	require.Equal(t, 7, intParam.Int(settings))
+	require.False(t, boolParam.Bool(settings))
+}
+
+func TestMetadataValidateParams(t *testing.T) {
+	meta := maps.SinkholesMap{}.Meta()
+	require.NoError(t, meta.ValidateParams(map[string]string{"sinkholes.maxRings": "2"}))
+	require.EqualError(t, meta.ValidateParams(map[string]string{"sinkholes.maxRings": "many"}), `parameter sinkholes.maxRings must be an integer, got "many"`)
+	require.EqualError(t, meta.ValidateParams(map[string]string{"sinkholes.size": "2"}), "map Sinkholes doesn't have a parameter named sinkholes.size")
+
+	// every parameter declared by a registered map has a valid default, and is namespaced by the map ID
+	for _, id := range maps.List() {
+		gameMap, err := maps.GetMap(id)
+		require.NoError(t, err)
+		for _, p := range gameMap.Meta().Parameters {
+			require.NoError(t, p.Validate(p.Default), id)
+			require.Regexp(t, "^"+id+`\.`, p.Name)
+		}
+	}
+}
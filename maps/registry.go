@@ -3,13 +3,20 @@ package maps
 import (
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/BattlesnakeOfficial/rules"
 )
 
-// MapRegistry is a mapping of map names to game maps.
+// MapRegistry is a mapping of map names to game maps. Older versions of
+// maps are kept under IDs like "sinkholes@1", so that games played with them
+// can still be replayed.
 type MapRegistry map[string]GameMap
 
+// MapVersionSeparator joins a map ID and a version, like "sinkholes@1".
+const MapVersionSeparator = "@"
+
 var globalRegistry = MapRegistry{}
 
 // RegisterMap adds a stage to the registry.
@@ -22,24 +29,120 @@ func (registry MapRegistry) RegisterMap(id string, m GameMap) {
 	registry[id] = m
 }
 
-// List returns all registered map IDs in alphabetical order
+// RegisterMapVersion adds an older version of a map to the registry, under
+// its ID and the version from its metadata. When a map changes, its Version
+// should be increased and the previous implementation registered with
+// RegisterMapVersion, so that games played with it can be replayed.
+// If the version has already been registered this will panic.
+func (registry MapRegistry) RegisterMapVersion(id string, m GameMap) {
+	registry.RegisterMap(VersionedMapID(id, m.Meta().Version), m)
+}
+
+// List returns all registered map IDs in alphabetical order. Older versions of maps aren't included.
 func (registry MapRegistry) List() []string {
 	var keys []string
 	for k := range registry {
+		if strings.Contains(k, MapVersionSeparator) {
+			continue
+		}
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
 	return keys
 }
 
-// GetMap returns the map associated with the given ID.
+// Versions returns the versions registered for a map ID, in ascending order.
+func (registry MapRegistry) Versions(id string) []int {
+	var versions []int
+	if m, ok := registry[id]; ok {
+		versions = append(versions, m.Meta().Version)
+	}
+	for k, m := range registry {
+		if strings.HasPrefix(k, id+MapVersionSeparator) {
+			versions = append(versions, m.Meta().Version)
+		}
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// GetMap returns the map associated with the given ID. IDs of registered maps
+// joined with CompositeMapSeparator, like "royale+healing_pools", return a
+// CompositeMap of those maps. A specific version of a map can be chosen with
+// an ID like "sinkholes@1".
 func (registry MapRegistry) GetMap(id string) (GameMap, error) {
 	if m, ok := registry[id]; ok {
 		return m, nil
 	}
+	if strings.Contains(id, CompositeMapSeparator) {
+		return registry.getCompositeMap(id)
+	}
+	if strings.Contains(id, MapVersionSeparator) {
+		return registry.getMapVersion(id)
+	}
 	return nil, rules.ErrorMapNotFound
 }
 
+// Look up a map ID with a version, like "sinkholes@1". Older versions are
+// registered under that ID, so this only needs to check the current version.
+func (registry MapRegistry) getMapVersion(id string) (GameMap, error) {
+	mapID, versionString, _ := strings.Cut(id, MapVersionSeparator)
+	version, err := strconv.Atoi(versionString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid map version in %s", id)
+	}
+	m, ok := registry[mapID]
+	if !ok {
+		return nil, rules.ErrorMapNotFound
+	}
+	if m.Meta().Version != version {
+		return nil, fmt.Errorf("%w: %s doesn't have version %d", rules.ErrorMapNotFound, mapID, version)
+	}
+	return m, nil
+}
+
+// VersionedMapID joins a map ID and a version, like "sinkholes@1".
+func VersionedMapID(id string, version int) string {
+	return id + MapVersionSeparator + strconv.Itoa(version)
+}
+
+// ResolvedMapID returns the ID that gets exactly this map from the registry,
+// including its version, like "sinkholes@1". For a composite map, each layer
+// has its version, like "royale@1+healing_pools@1".
+func ResolvedMapID(m GameMap) string {
+	if composite, ok := m.(*CompositeMap); ok {
+		ids := make([]string, 0, len(composite.layers))
+		for _, layer := range composite.layers {
+			ids = append(ids, ResolvedMapID(layer))
+		}
+		return strings.Join(ids, CompositeMapSeparator)
+	}
+	return VersionedMapID(m.ID(), m.Meta().Version)
+}
+
+// GameMapID returns the ID of a map to send to snakes in client.Game.Map. It's
+// like ResolvedMapID, but only includes the version of maps that aren't the
+// current version in the registry, like "royale+snail_mode@1". Games on the
+// current version of a map use its plain ID, as they did before maps had versions.
+func (registry MapRegistry) GameMapID(m GameMap) string {
+	if composite, ok := m.(*CompositeMap); ok {
+		ids := make([]string, 0, len(composite.layers))
+		for _, layer := range composite.layers {
+			ids = append(ids, registry.GameMapID(layer))
+		}
+		return strings.Join(ids, CompositeMapSeparator)
+	}
+	if current, ok := registry[m.ID()]; ok && current.Meta().Version == m.Meta().Version {
+		return m.ID()
+	}
+	return ResolvedMapID(m)
+}
+
+// GameMapID returns the ID of a map to send to snakes, using the global registry. See MapRegistry.GameMapID.
+func GameMapID(m GameMap) string {
+	return globalRegistry.GameMapID(m)
+}
+
 // GetMap returns the map associated with the given ID from the global registry.
 func GetMap(id string) (GameMap, error) {
 	return globalRegistry.GetMap(id)
@@ -55,6 +158,16 @@ func RegisterMap(id string, m GameMap) {
 	globalRegistry.RegisterMap(id, m)
 }
 
+// RegisterMapVersion adds an older version of a map to the global registry.
+func RegisterMapVersion(id string, m GameMap) {
+	globalRegistry.RegisterMapVersion(id, m)
+}
+
+// Versions returns the versions of a map in the global registry.
+func Versions(id string) []int {
+	return globalRegistry.Versions(id)
+}
+
 func TestMap(id string, m GameMap, callback func()) {
 	globalRegistry[id] = m
 	callback()
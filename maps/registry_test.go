@@ -2,6 +2,7 @@ package maps
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/BattlesnakeOfficial/rules"
@@ -20,9 +21,15 @@ var testSettings rules.Settings = rules.NewSettings(map[string]string{
 func TestRegisteredMaps(t *testing.T) {
 	for mapName, gameMap := range globalRegistry {
 		t.Run(mapName, func(t *testing.T) {
-			require.Equalf(t, mapName, gameMap.ID(), "%#v game map doesn't return its own ID", mapName)
+			// older versions are registered under IDs like "sinkholes@1"
+			id, version, isOldVersion := strings.Cut(mapName, MapVersionSeparator)
+			require.Equalf(t, id, gameMap.ID(), "%#v game map doesn't return its own ID", mapName)
 			meta := gameMap.Meta()
 			require.True(t, meta.Version > 0, fmt.Sprintf("registered maps must have a valid version (>= 1) - '%d' is invalid", meta.Version))
+			if isOldVersion {
+				require.Equal(t, version, fmt.Sprint(meta.Version), "older versions must be registered under their own version")
+				require.Less(t, meta.Version, globalRegistry[id].Meta().Version, "older versions must be older than the current version")
+			}
 			require.NotZero(t, meta.MaxPlayers, "registered maps must have maximum players declared")
 			require.LessOrEqual(t, meta.MaxPlayers, meta.MaxPlayers, "max players should always be >= min players")
 			require.NotEmpty(t, meta.BoardSizes, "registered maps must have at least one supported size declared")
@@ -115,10 +122,66 @@ func TestListRegisteredMaps(t *testing.T) {
 	keys := globalRegistry.List()
 	mapCount := 0
 	for k := range globalRegistry {
+		if strings.Contains(k, MapVersionSeparator) {
+			continue
+		}
 		// every registry key should exist in List results
 		require.Contains(t, keys, k)
 		mapCount++
 	}
-	// List should equal number of maps in the global registry
+	// List should equal number of current maps in the global registry
 	require.Equal(t, len(keys), mapCount)
 }
+
+// A StubMap with a version.
+type versionedStubMap struct {
+	StubMap
+	version int
+}
+
+func (m versionedStubMap) Meta() Metadata {
+	return Metadata{Version: m.version, MaxPlayers: 8, BoardSizes: AnySize()}
+}
+
+func TestMapVersions(t *testing.T) {
+	registry := MapRegistry{}
+	current := versionedStubMap{StubMap{Id: "stub"}, 3}
+	first := versionedStubMap{StubMap{Id: "stub"}, 1}
+	registry.RegisterMap("stub", current)
+	registry.RegisterMapVersion("stub", first)
+	require.Panics(t, func() { registry.RegisterMapVersion("stub", first) })
+
+	// older versions are hidden from the list
+	require.Equal(t, []string{"stub"}, registry.List())
+	require.Equal(t, []int{1, 3}, registry.Versions("stub"))
+
+	m, err := registry.GetMap("stub")
+	require.NoError(t, err)
+	require.Equal(t, current, m)
+	m, err = registry.GetMap("stub@1")
+	require.NoError(t, err)
+	require.Equal(t, first, m)
+	m, err = registry.GetMap("stub@3")
+	require.NoError(t, err)
+	require.Equal(t, current, m)
+
+	_, err = registry.GetMap("stub@2")
+	require.ErrorIs(t, err, rules.ErrorMapNotFound)
+	require.EqualError(t, err, "map not found: stub doesn't have version 2")
+	_, err = registry.GetMap("missing@1")
+	require.ErrorIs(t, err, rules.ErrorMapNotFound)
+	_, err = registry.GetMap("stub@latest")
+	require.EqualError(t, err, "invalid map version in stub@latest")
+
+	// versions can be chosen for each layer of a composite map
+	registry.RegisterMap("other", versionedStubMap{StubMap{Id: "other"}, 2})
+	m, err = registry.GetMap("stub@1+other")
+	require.NoError(t, err)
+	require.Equal(t, "stub+other", m.ID())
+	require.Equal(t, "stub@1+other@2", ResolvedMapID(m))
+	require.Equal(t, "stub@3", ResolvedMapID(current))
+
+	// snakes are only sent the version for older versions
+	require.Equal(t, "stub@1+other", registry.GameMapID(m))
+	require.Equal(t, "stub", registry.GameMapID(current))
+}
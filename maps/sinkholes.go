@@ -8,6 +8,21 @@ import (
 
 type SinkholesMap struct{}
 
+var (
+	sinkholesSpawnEveryNTurns = Parameter{
+		Name:        "sinkholes.spawnEveryNTurns",
+		Type:        ParameterTypeInt,
+		Default:     "0",
+		Description: "Number of turns between the sinkhole growing. 0 uses the shrinkEveryNTurns setting, or 10 if it isn't set",
+	}
+	sinkholesMaxRings = Parameter{
+		Name:        "sinkholes.maxRings",
+		Type:        ParameterTypeInt,
+		Default:     "0",
+		Description: "Limit on the size of the sinkhole, which grows until it's one ring smaller than this. 0 uses 3, 5 or 7 depending on the board size",
+	}
+)
+
 func init() {
 	globalRegistry.RegisterMap("sinkholes", SinkholesMap{})
 }
@@ -26,6 +41,7 @@ func (m SinkholesMap) Meta() Metadata {
 		MaxPlayers:  8,
 		BoardSizes:  FixedSizes(Dimensions{7, 7}, Dimensions{11, 11}, Dimensions{19, 19}),
 		Tags:        []string{TAG_HAZARD_PLACEMENT},
+		Parameters:  []Parameter{sinkholesSpawnEveryNTurns, sinkholesMaxRings},
 	}
 }
 
@@ -45,16 +61,29 @@ func (m SinkholesMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings
 
 	currentTurn := lastBoardState.Turn
 	startTurn := 1
-	spawnEveryNTurns := 10
-	shrinkEveryNTurns := settings.Int(rules.ParamShrinkEveryNTurns, 0)
-	if shrinkEveryNTurns > 0 {
-		spawnEveryNTurns = shrinkEveryNTurns
+	spawnEveryNTurns := sinkholesSpawnEveryNTurns.Int(settings)
+	if spawnEveryNTurns <= 0 {
+		spawnEveryNTurns = 10
+		shrinkEveryNTurns := settings.Int(rules.ParamShrinkEveryNTurns, 0)
+		if shrinkEveryNTurns > 0 {
+			spawnEveryNTurns = shrinkEveryNTurns
+		}
+	}
+	maxRings := sinkholesMaxRings.Int(settings)
+	if maxRings <= 0 {
+		maxRings = 5
+		if lastBoardState.Width == 7 {
+			maxRings = 3
+		} else if lastBoardState.Width == 19 {
+			maxRings = 7
+		}
+	}
+	// Keep the sinkhole on the board
+	if maxRings > lastBoardState.Width/2 {
+		maxRings = lastBoardState.Width / 2
 	}
-	maxRings := 5
-	if lastBoardState.Width == 7 {
-		maxRings = 3
-	} else if lastBoardState.Width == 19 {
-		maxRings = 7
+	if maxRings > lastBoardState.Height/2 {
+		maxRings = lastBoardState.Height / 2
 	}
 
 	spawnLocation := rules.Point{X: lastBoardState.Width / 2, Y: lastBoardState.Height / 2}
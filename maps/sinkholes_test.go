@@ -55,3 +55,23 @@ func TestSinkholesMap(t *testing.T) {
 		})
 	}
 }
+
+func TestSinkholesMapParameters(t *testing.T) {
+	m := maps.SinkholesMap{}
+	state := rules.NewBoardState(11, 11)
+	settings := rules.NewSettingsWithParams("sinkholes.spawnEveryNTurns", "2", "sinkholes.maxRings", "2")
+	editor := maps.NewBoardStateEditor(state)
+	require.NoError(t, m.SetupBoard(state, settings, editor))
+
+	// the center is added on the first turn, then a ring two turns later. The
+	// sinkhole stops growing one ring short of maxRings, as it does with the
+	// default sizes.
+	for i := 0; i < 100; i++ {
+		state.Turn = i
+		require.NoError(t, m.PostUpdateBoard(state, settings, editor))
+		if i == 1 {
+			require.Len(t, state.Hazards, 1)
+		}
+	}
+	require.Len(t, state.Hazards, 1+5)
+}
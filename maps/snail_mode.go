@@ -6,9 +6,23 @@ import (
 
 type SnailModeMap struct{}
 
+// The tail of a snake, where hazards are placed on the next turn.
+type snailModeTail struct {
+	Point rules.Point
+	Stack int // number of hazards to stack on the tail
+}
+
+var snailModeTrailLength = Parameter{
+	Name:        "snail_mode.trailLength",
+	Type:        ParameterTypeInt,
+	Default:     "0",
+	Description: "Number of turns the hazards left behind a snake last for. 0 uses the length of the snake",
+}
+
 // init registers this map in the global registry.
 func init() {
 	globalRegistry.RegisterMap("snail_mode", SnailModeMap{})
+	globalRegistry.RegisterMapVersion("snail_mode", snailModeMapV1{})
 }
 
 // ID returns a unique identifier for this map.
@@ -22,11 +36,12 @@ func (m SnailModeMap) Meta() Metadata {
 		Name:        "Snail Mode",
 		Description: "Snakes leave behind a trail of hazards",
 		Author:      "coreyja and jlafayette",
-		Version:     1,
+		Version:     2,
 		MinPlayers:  1,
 		MaxPlayers:  16,
 		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
 		Tags:        []string{TAG_EXPERIMENTAL, TAG_HAZARD_PLACEMENT},
+		Parameters:  []Parameter{snailModeTrailLength},
 	}
 }
 
@@ -36,18 +51,6 @@ func (m SnailModeMap) SetupBoard(initialBoardState *rules.BoardState, settings r
 	return StandardMap{}.SetupBoard(initialBoardState, settings, editor)
 }
 
-// storeTailLocation returns an offboard point that corresponds to the given point.
-// This is useful for storing state that can be accessed next turn.
-func storeTailLocation(point rules.Point, height int) rules.Point {
-	return rules.Point{X: point.X, Y: point.Y + height}
-}
-
-// getPrevTailLocation returns the onboard point that corresponds to an offboard point.
-// This is useful for restoring state that was stored last turn.
-func getPrevTailLocation(point rules.Point, height int) rules.Point {
-	return rules.Point{X: point.X, Y: point.Y - height}
-}
-
 // outOfBounds determines if the given point is out of bounds for the current board size
 func outOfBounds(p rules.Point, w, h int) bool {
 	return p.X < 0 || p.Y < 0 || p.X >= w || p.Y >= h
@@ -70,35 +73,39 @@ func (m SnailModeMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings
 }
 
 // PostUpdateBoard does the work of placing the hazards along the 'snail tail' of snakes
-// This is responsible for saving the current tail location off the board
+// This is responsible for saving the current tail location in the map state
 // and restoring the previous tail position. This also handles removing one hazards from
 // the current stacks so the hazards tails fade as the snake moves away.
 func (m SnailModeMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
-	err := StandardMap{}.PostUpdateBoard(lastBoardState, settings, editor)
+	state := editor.MapState(m.ID())
+	var previousTails []snailModeTail
+	if _, err := state.Load("tails", &previousTails); err != nil {
+		return err
+	}
+	tails, err := snailModeUpdate(lastBoardState, settings, editor, previousTails, snailModeTrailLength.Int(settings))
 	if err != nil {
 		return err
 	}
+	return state.Store("tails", tails)
+}
+
+// Place the hazards for the tails from the previous turn, and fade the older
+// hazards. Returns the tails to place hazards on next turn, with a stack of
+// trailLength hazards, or the length of the snake when it's 0.
+func snailModeUpdate(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor, previousTails []snailModeTail, trailLength int) ([]snailModeTail, error) {
+	err := StandardMap{}.PostUpdateBoard(lastBoardState, settings, editor)
+	if err != nil {
+		return nil, err
+	}
 
 	// This map decrements the stack of hazards on a point each turn, so they
 	// need to be cleared first.
 	editor.ClearHazards()
 
-	// This is a list of all the hazards we want to add for the previous tails
-	// These were stored off board in the previous turn as a way to save state
-	// When we add the locations to this list we have already converted the off-board
-	// points to on-board points
-	tailLocations := make([]rules.Point, 0, len(lastBoardState.Snakes))
-
 	// Count the number of hazards for a given position
-	// Add non-double tail locations to a slice
 	hazardCounts := map[rules.Point]int{}
 	for _, hazard := range lastBoardState.Hazards {
-
-		// discard out of bound
-		if outOfBounds(hazard, lastBoardState.Width, lastBoardState.Height) {
-			onBoardTail := getPrevTailLocation(hazard, lastBoardState.Height)
-			tailLocations = append(tailLocations, onBoardTail)
-		} else {
+		if !outOfBounds(hazard, lastBoardState.Width, lastBoardState.Height) {
 			hazardCounts[hazard]++
 		}
 	}
@@ -112,9 +119,10 @@ func (m SnailModeMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings
 		}
 	}
 
-	// Store a stack of hazards for the tail of each snake.  This is stored out
-	// of bounds and then applied on the next turn.  The stack count is equal
-	// the lenght of the snake.
+	// Store a stack of hazards for the tail of each snake, which is applied on
+	// the next turn.  The stack count is equal the lenght of the snake, unless
+	// the trail length is set.
+	tails := make([]snailModeTail, 0, len(lastBoardState.Snakes))
 	for _, snake := range lastBoardState.Snakes {
 		if isEliminated(&snake) {
 			continue
@@ -126,16 +134,17 @@ func (m SnailModeMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings
 			continue
 		}
 
-		tail := snake.Body[len(snake.Body)-1]
-		offBoardTail := storeTailLocation(tail, lastBoardState.Height)
-		for i := 0; i < len(snake.Body); i++ {
-			editor.AddHazard(offBoardTail)
+		stack := trailLength
+		if stack <= 0 {
+			stack = len(snake.Body)
 		}
+		tails = append(tails, snailModeTail{Point: snake.Body[len(snake.Body)-1], Stack: stack})
 	}
 
-	// Read offboard tails and move them to the board. The offboard tails are
-	// stacked based on the length of the snake
-	for _, p := range tailLocations {
+	// Place the tails from the previous turn on the board, stacked based on
+	// the length of the snake
+	for _, tail := range previousTails {
+		p := tail.Point
 
 		// Skip position if a snakes head occupies it.
 		// Otherwise hazard shows up in the viewer on top of a snake head, but
@@ -155,8 +164,10 @@ func (m SnailModeMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings
 			continue
 		}
 
-		editor.AddHazard(p)
+		for i := 0; i < tail.Stack; i++ {
+			editor.AddHazard(p)
+		}
 	}
 
-	return nil
+	return tails, nil
 }
@@ -0,0 +1,83 @@
+package maps_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+// Move a snake one step right along its row, and update the board with a map.
+func snailModeTurns(t *testing.T, gameMap maps.GameMap, turns int) []*rules.BoardState {
+	boardState := rules.NewBoardState(11, 11).WithSnakes([]rules.Snake{
+		{ID: "one", Body: []rules.Point{{X: 2, Y: 5}, {X: 1, Y: 5}, {X: 0, Y: 5}}, Health: 100},
+	})
+	var boardStates []*rules.BoardState
+	for turn := 0; turn < turns; turn++ {
+		next := boardState.Clone()
+		next.Turn = turn
+		snake := &next.Snakes[0]
+		head := snake.Body[0]
+		snake.Body = append([]rules.Point{{X: head.X + 1, Y: head.Y}}, snake.Body[:len(snake.Body)-1]...)
+
+		var err error
+		boardState, err = maps.PostUpdateBoard(gameMap, next, rules.Settings{})
+		require.NoError(t, err)
+		boardStates = append(boardStates, boardState)
+	}
+	return boardStates
+}
+
+func onBoardHazards(boardState *rules.BoardState) []rules.Point {
+	var hazards []rules.Point
+	for _, p := range boardState.Hazards {
+		if p.X >= 0 && p.Y >= 0 && p.X < boardState.Width && p.Y < boardState.Height {
+			hazards = append(hazards, p)
+		}
+	}
+	sort.Slice(hazards, func(i, j int) bool {
+		return hazards[i].X < hazards[j].X
+	})
+	return hazards
+}
+
+func TestSnailModeMap(t *testing.T) {
+	boardStates := snailModeTurns(t, maps.SnailModeMap{}, 5)
+
+	// the tail is kept in the map state, and hazards are placed behind the snake a turn later
+	require.Equal(t, `[{"Point":{"X":1,"Y":5},"Stack":3}]`, boardStates[0].GameState["snail_mode.tails"])
+	require.Empty(t, boardStates[0].Hazards)
+	require.Equal(t, []rules.Point{{X: 1, Y: 5}, {X: 1, Y: 5}, {X: 1, Y: 5}}, boardStates[1].Hazards)
+
+	// older hazards fade as the snake moves away
+	last := boardStates[len(boardStates)-1]
+	require.Equal(t, []rules.Point{
+		{X: 2, Y: 5}, {X: 3, Y: 5}, {X: 3, Y: 5}, {X: 4, Y: 5}, {X: 4, Y: 5}, {X: 4, Y: 5},
+	}, onBoardHazards(last))
+	require.Len(t, last.Hazards, 6)
+}
+
+func TestSnailModeMapV1(t *testing.T) {
+	v1, err := maps.GetMap("snail_mode@1")
+	require.NoError(t, err)
+	require.Equal(t, 1, v1.Meta().Version)
+	require.Empty(t, v1.Meta().Parameters, "the trail length was added in version 2")
+	require.Equal(t, maps.SnailModeMap{}.Meta().BoardSizes, v1.Meta().BoardSizes)
+
+	// version 1 places the same hazards on the board, but stores tails as hazards off the board
+	v1States := snailModeTurns(t, v1, 5)
+	v2States := snailModeTurns(t, maps.SnailModeMap{}, 5)
+	for i := range v1States {
+		require.Equal(t, onBoardHazards(v2States[i]), onBoardHazards(v1States[i]), "turn %d", i)
+		require.Empty(t, v1States[i].GameState)
+	}
+	require.Contains(t, v1States[0].Hazards, rules.Point{X: 1, Y: 16})
+
+	// hazards are in the same order as the original implementation, with the
+	// new tails off the board before the previous tails on the board
+	require.Equal(t, []rules.Point{
+		{X: 2, Y: 16}, {X: 2, Y: 16}, {X: 2, Y: 16}, {X: 1, Y: 5}, {X: 1, Y: 5}, {X: 1, Y: 5},
+	}, v1States[1].Hazards)
+}
@@ -0,0 +1,127 @@
+package maps
+
+import (
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// Version 1 of snail mode, which stored the tail of each snake between turns
+// as hazards off the edge of the board, and didn't have a trail length
+// parameter. Kept so that games played with it can be replayed.
+type snailModeMapV1 struct {
+	SnailModeMap
+}
+
+func (m snailModeMapV1) Meta() Metadata {
+	return Metadata{
+		Name:        "Snail Mode",
+		Description: "Snakes leave behind a trail of hazards",
+		Author:      "coreyja and jlafayette",
+		Version:     1,
+		MinPlayers:  1,
+		MaxPlayers:  16,
+		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
+		Tags:        []string{TAG_EXPERIMENTAL, TAG_HAZARD_PLACEMENT},
+	}
+}
+
+// storeTailLocation returns an offboard point that corresponds to the given point.
+// This is useful for storing state that can be accessed next turn.
+func storeTailLocation(point rules.Point, height int) rules.Point {
+	return rules.Point{X: point.X, Y: point.Y + height}
+}
+
+// getPrevTailLocation returns the onboard point that corresponds to an offboard point.
+// This is useful for restoring state that was stored last turn.
+func getPrevTailLocation(point rules.Point, height int) rules.Point {
+	return rules.Point{X: point.X, Y: point.Y - height}
+}
+
+// PostUpdateBoard is the original implementation, kept as it was so that
+// games played with it place their hazards in the same order.
+func (m snailModeMapV1) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	err := StandardMap{}.PostUpdateBoard(lastBoardState, settings, editor)
+	if err != nil {
+		return err
+	}
+
+	// This map decrements the stack of hazards on a point each turn, so they
+	// need to be cleared first.
+	editor.ClearHazards()
+
+	// This is a list of all the hazards we want to add for the previous tails
+	// These were stored off board in the previous turn as a way to save state
+	// When we add the locations to this list we have already converted the off-board
+	// points to on-board points
+	tailLocations := make([]rules.Point, 0, len(lastBoardState.Snakes))
+
+	// Count the number of hazards for a given position
+	// Add non-double tail locations to a slice
+	hazardCounts := map[rules.Point]int{}
+	for _, hazard := range lastBoardState.Hazards {
+
+		// discard out of bound
+		if outOfBounds(hazard, lastBoardState.Width, lastBoardState.Height) {
+			onBoardTail := getPrevTailLocation(hazard, lastBoardState.Height)
+			tailLocations = append(tailLocations, onBoardTail)
+		} else {
+			hazardCounts[hazard]++
+		}
+	}
+
+	// Add back existing hazards, but with a stack of 1 less than before.
+	// This has the effect of making the snail-trail disappear over time.
+	for hazard, count := range hazardCounts {
+
+		for i := 0; i < count-1; i++ {
+			editor.AddHazard(hazard)
+		}
+	}
+
+	// Store a stack of hazards for the tail of each snake.  This is stored out
+	// of bounds and then applied on the next turn.  The stack count is equal
+	// the lenght of the snake.
+	for _, snake := range lastBoardState.Snakes {
+		if isEliminated(&snake) {
+			continue
+		}
+
+		// Double tail means that the tail will stay on the same square for more
+		// than one turn, so we don't want to spawn hazards
+		if doubleTail(&snake) {
+			continue
+		}
+
+		tail := snake.Body[len(snake.Body)-1]
+		offBoardTail := storeTailLocation(tail, lastBoardState.Height)
+		for i := 0; i < len(snake.Body); i++ {
+			editor.AddHazard(offBoardTail)
+		}
+	}
+
+	// Read offboard tails and move them to the board. The offboard tails are
+	// stacked based on the length of the snake
+	for _, p := range tailLocations {
+
+		// Skip position if a snakes head occupies it.
+		// Otherwise hazard shows up in the viewer on top of a snake head, but
+		// does not damage the snake, which is visually confusing.
+		isHead := false
+		for _, snake := range lastBoardState.Snakes {
+			if isEliminated(&snake) {
+				continue
+			}
+			head := snake.Body[0]
+			if p.X == head.X && p.Y == head.Y {
+				isHead = true
+				break
+			}
+		}
+		if isHead {
+			continue
+		}
+
+		editor.AddHazard(p)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package maps
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// MapState stores values that a map needs to keep between turns, such as the
+// positions of things that move. Values are serialised as JSON in the board's
+// GameState, under keys prefixed with a namespace, usually the map's ID, so
+// that maps combined in a CompositeMap can't overwrite each other's state.
+//
+// Because it's part of the board, map state is copied by BoardState.Clone,
+// serialised with the board, and recorded in game exports. When a map is
+// updated with the PreUpdateBoard and PostUpdateBoard helpers:
+//   - State stored in SetupBoard is visible in PreUpdateBoard on the first turn.
+//   - State stored in PreUpdateBoard is visible in PostUpdateBoard on the same turn.
+//   - State stored in PostUpdateBoard is visible in PreUpdateBoard on the next turn.
+//
+// State stored on a turn where the map returns an error is discarded along
+// with the rest of the map's changes.
+type MapState struct {
+	boardState *rules.BoardState // its GameState is created the first time a value is stored
+	namespace  string
+}
+
+// NewMapState gets the state for a namespace from a board. To change the
+// state from a map, use the Editor's MapState method instead. Loading values
+// doesn't change the board, so this can be used to read the state of boards
+// that shouldn't be modified.
+func NewMapState(boardState *rules.BoardState, namespace string) MapState {
+	return MapState{boardState: boardState, namespace: namespace}
+}
+
+func (s MapState) values() map[string]string {
+	return s.boardState.GameState
+}
+
+func (s MapState) key(key string) string {
+	return s.namespace + "." + key
+}
+
+// Load the value stored under a key into value, which should be a pointer.
+// Returns false if nothing has been stored under the key, and leaves value
+// unchanged.
+func (s MapState) Load(key string, value interface{}) (bool, error) {
+	stored, ok := s.values()[s.key(key)]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(stored), value); err != nil {
+		return false, fmt.Errorf("invalid map state %s: %w", s.key(key), err)
+	}
+	return true, nil
+}
+
+// Store a value under a key, replacing any value stored before.
+func (s MapState) Store(key string, value interface{}) error {
+	stored, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("can't store map state %s: %w", s.key(key), err)
+	}
+	if s.boardState.GameState == nil {
+		s.boardState.GameState = map[string]string{}
+	}
+	s.boardState.GameState[s.key(key)] = string(stored)
+	return nil
+}
+
+// Delete the value stored under a key.
+func (s MapState) Delete(key string) {
+	delete(s.values(), s.key(key))
+}
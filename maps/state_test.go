@@ -0,0 +1,110 @@
+package maps_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+type counterState struct {
+	Count int
+	Calls []string
+}
+
+// Counts the calls to each method in its map state, and checks the state
+// stored by the previous call is visible.
+type counterMap struct {
+	maps.StubMap
+	t *testing.T
+}
+
+func (m counterMap) record(editor maps.Editor, call string, expectedCount int) error {
+	state := editor.MapState(m.Id)
+	var counter counterState
+	found, err := state.Load("counter", &counter)
+	if err != nil {
+		return err
+	}
+	require.Equal(m.t, expectedCount > 0, found)
+	require.Equal(m.t, expectedCount, counter.Count)
+	counter.Count++
+	counter.Calls = append(counter.Calls, call)
+	return state.Store("counter", counter)
+}
+
+func (m counterMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	return m.record(editor, "setup", 0)
+}
+
+func (m counterMap) PreUpdateBoard(previousBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	return m.record(editor, "pre", 2*previousBoardState.Turn+1)
+}
+
+func (m counterMap) PostUpdateBoard(previousBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	return m.record(editor, "post", 2*previousBoardState.Turn+2)
+}
+
+func TestMapStateVisibility(t *testing.T) {
+	m := counterMap{StubMap: maps.StubMap{Id: "counter"}, t: t}
+	boardState := rules.NewBoardState(7, 7)
+	require.NoError(t, m.SetupBoard(boardState.Clone(), rules.Settings{}, maps.NewBoardStateEditor(boardState)))
+
+	var err error
+	for turn := 0; turn < 3; turn++ {
+		boardState.Turn = turn
+		boardState, err = maps.PreUpdateBoard(m, boardState, rules.Settings{})
+		require.NoError(t, err)
+		boardState, err = maps.PostUpdateBoard(m, boardState, rules.Settings{})
+		require.NoError(t, err)
+	}
+
+	// the state is kept when the board is serialised
+	serialised, err := json.Marshal(boardState)
+	require.NoError(t, err)
+	restored := &rules.BoardState{}
+	require.NoError(t, json.Unmarshal(serialised, restored))
+
+	var counter counterState
+	found, err := maps.NewMapState(restored, "counter").Load("counter", &counter)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, counterState{Count: 7, Calls: []string{"setup", "pre", "post", "pre", "post", "pre", "post"}}, counter)
+}
+
+func TestMapStateNamespaces(t *testing.T) {
+	boardState := rules.NewBoardState(7, 7)
+	editor := maps.NewBoardStateEditor(boardState)
+
+	require.NoError(t, editor.MapState("first").Store("value", 1))
+	require.NoError(t, editor.MapState("second").Store("value", []rules.Point{{X: 1, Y: 2}}))
+	require.Equal(t, map[string]string{"first.value": "1", "second.value": `[{"X":1,"Y":2}]`}, boardState.GameState)
+
+	var value int
+	found, err := editor.MapState("first").Load("value", &value)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, 1, value)
+
+	// values of the wrong type can't be loaded
+	_, err = editor.MapState("second").Load("value", &value)
+	require.ErrorContains(t, err, "invalid map state second.value")
+
+	editor.MapState("first").Delete("value")
+	found, err = editor.MapState("first").Load("value", &value)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	// state can be read from boards without a GameState, without changing them
+	boardState = &rules.BoardState{Width: 7, Height: 7}
+	found, err = maps.NewMapState(boardState, "first").Load("value", &value)
+	require.NoError(t, err)
+	require.False(t, found)
+	require.Nil(t, boardState.GameState)
+
+	// and stored on them
+	require.NoError(t, maps.NewBoardStateEditor(boardState).MapState("first").Store("value", 2))
+	require.Equal(t, map[string]string{"first.value": "2"}, boardState.GameState)
+}
@@ -0,0 +1,76 @@
+package maps
+
+import (
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+type TurnstileMap struct{}
+
+var turnstileRotateEveryNTurns = Parameter{
+	Name:        "turnstile.rotateEveryNTurns",
+	Type:        ParameterTypeInt,
+	Default:     "5",
+	Description: "Number of turns between the turnstile turning a quarter turn",
+}
+
+func init() {
+	globalRegistry.RegisterMap("turnstile", TurnstileMap{})
+}
+
+func (m TurnstileMap) ID() string {
+	return "turnstile"
+}
+
+func (m TurnstileMap) Meta() Metadata {
+	return Metadata{
+		Name:        "Turnstile",
+		Description: "A wall of hazards in the middle of the board that turns a quarter turn around its center every few turns.",
+		Author:      "Battlesnake",
+		Version:     1,
+		MinPlayers:  1,
+		MaxPlayers:  8,
+		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
+		Tags:        []string{TAG_EXPERIMENTAL, TAG_HAZARD_PLACEMENT},
+		Parameters:  []Parameter{turnstileRotateEveryNTurns},
+	}
+}
+
+func (m TurnstileMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if err := (StandardMap{}).SetupBoard(initialBoardState, settings, editor); err != nil {
+		return err
+	}
+
+	// A bar through the center, which turns around its first cell. Its arms
+	// are a quarter of the board long, so they stay clear of the start
+	// positions at the edges of the board.
+	center := rules.Point{X: initialBoardState.Width / 2, Y: initialBoardState.Height / 2}
+	armLength := initialBoardState.Width / 4
+	if armLength < 1 {
+		armLength = 1
+	}
+	body := []rules.Point{center}
+	for i := 1; i <= armLength; i++ {
+		body = append(body, rules.Point{X: center.X, Y: center.Y + i}, rules.Point{X: center.X, Y: center.Y - i})
+	}
+	turnstile := Entity{
+		ID:     "turnstile",
+		Body:   body,
+		Path:   EntityPathRotate,
+		Period: turnstileRotateEveryNTurns.Int(settings),
+	}
+
+	// The standard map places food in the center, which is covered by the turnstile
+	for _, p := range turnstile.Body {
+		editor.RemoveFood(p)
+	}
+
+	return PlaceEntity(editor, turnstile)
+}
+
+func (m TurnstileMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return nil
+}
+
+func (m TurnstileMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return StandardMap{}.PostUpdateBoard(lastBoardState, settings, editor)
+}
@@ -0,0 +1,38 @@
+package maps_test
+
+import (
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTurnstileMap(t *testing.T) {
+	m := maps.TurnstileMap{}
+	settings := rules.NewSettingsWithParams("turnstile.rotateEveryNTurns", "2", rules.ParamMinimumFood, "1").WithSeed(1)
+	boardState, err := maps.SetupBoardWithMap(m, settings, 11, 11, []string{"one", "two", "three", "four"})
+	require.NoError(t, err)
+
+	vertical := []rules.Point{{X: 5, Y: 5}, {X: 5, Y: 6}, {X: 5, Y: 4}, {X: 5, Y: 7}, {X: 5, Y: 3}}
+	horizontal := []rules.Point{{X: 5, Y: 5}, {X: 6, Y: 5}, {X: 4, Y: 5}, {X: 7, Y: 5}, {X: 3, Y: 5}}
+	require.ElementsMatch(t, vertical, boardState.Hazards)
+	for _, snake := range boardState.Snakes {
+		require.NotContains(t, vertical, snake.Body[0])
+		require.NotContains(t, horizontal, snake.Body[0])
+	}
+	for _, food := range boardState.Food {
+		require.NotContains(t, vertical, food)
+	}
+
+	// the turnstile turns a quarter turn every other turn
+	expected := [][]rules.Point{horizontal, horizontal, vertical, vertical, horizontal}
+	for turn, hazards := range expected {
+		boardState.Turn = turn
+		boardState, err = maps.PreUpdateBoard(m, boardState, settings)
+		require.NoError(t, err)
+		boardState, err = maps.PostUpdateBoard(m, boardState, settings)
+		require.NoError(t, err)
+		require.ElementsMatch(t, hazards, boardState.Hazards, "turn %d", turn)
+	}
+}
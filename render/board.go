@@ -0,0 +1,154 @@
+// Package render draws boards as images, for sharing games outside of the
+// terminal and the game board.
+package render
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// Default size of each board cell, in pixels.
+const DefaultCellSize = 20
+
+var (
+	backgroundColor = color.RGBA{0xd0, 0xd0, 0xd0, 0xff}
+	cellColor       = color.RGBA{0xf4, 0xf4, 0xf4, 0xff}
+	hazardColor     = color.RGBA{0x9a, 0x9a, 0x9a, 0xff}
+	foodColor       = color.RGBA{0xff, 0x5a, 0x5f, 0xff}
+	// Used for snake eyes, and crosses on eliminated snakes
+	markColor = color.RGBA{0x20, 0x20, 0x20, 0xff}
+
+	// Default gray color from Battlesnake board
+	defaultSnakeColor = color.RGBA{0x88, 0x88, 0x88, 0xff}
+)
+
+// Options for drawing a board.
+type Options struct {
+	// Size of each board cell, in pixels. DefaultCellSize is used if it's zero.
+	CellSize int
+	// Color of each snake by ID, as a hex string like "#ef03d3". Snakes
+	// without a valid color are drawn in gray.
+	SnakeColors map[string]string
+}
+
+func (options Options) cellSize() int {
+	if options.CellSize <= 0 {
+		return DefaultCellSize
+	}
+	return options.CellSize
+}
+
+func (options Options) snakeColor(id string) color.RGBA {
+	if c, ok := ParseColor(options.SnakeColors[id]); ok {
+		return c
+	}
+	return defaultSnakeColor
+}
+
+// Parse a color string like "#ef03d3".
+func ParseColor(hex string) (color.RGBA, bool) {
+	if len(hex) != 7 || hex[0] != '#' {
+		return color.RGBA{}, false
+	}
+	value, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xff}, true
+}
+
+// The color an eliminated snake is drawn in.
+func fadeColor(c color.RGBA) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8((uint16(a) + 2*uint16(b)) / 3)
+	}
+	return color.RGBA{mix(c.R, cellColor.R), mix(c.G, cellColor.G), mix(c.B, cellColor.B), 0xff}
+}
+
+// Something the board can be drawn on, like an image or an SVG document.
+type canvas interface {
+	fillRect(rect image.Rectangle, c color.RGBA)
+	fillCircle(center image.Point, radius int, c color.RGBA)
+	drawCross(rect image.Rectangle, c color.RGBA)
+}
+
+// Draws shapes on board cells. Boards have y increasing upwards, and images
+// have it increasing downwards.
+type grid struct {
+	canvas   canvas
+	cellSize int
+	height   int
+	// Position of the top left corner of the board in the canvas
+	origin image.Point
+}
+
+func (g grid) cellRect(p rules.Point) image.Rectangle {
+	x := g.origin.X + p.X*g.cellSize
+	y := g.origin.Y + (g.height-1-p.Y)*g.cellSize
+	return image.Rect(x, y, x+g.cellSize, y+g.cellSize)
+}
+
+func (g grid) cellCenter(p rules.Point) image.Point {
+	rect := g.cellRect(p)
+	return rect.Min.Add(rect.Size().Div(2))
+}
+
+// Draw the cells, hazards, food and snakes on a board. Snakes eliminated on
+// the board's turn are drawn faded and crossed out, and snakes eliminated on
+// earlier turns aren't drawn.
+func (g grid) drawBoard(boardState *rules.BoardState, options Options) {
+	for y := 0; y < boardState.Height; y++ {
+		for x := 0; x < boardState.Width; x++ {
+			g.canvas.fillRect(g.cellRect(rules.Point{X: x, Y: y}).Inset(1), cellColor)
+		}
+	}
+	for _, p := range boardState.Hazards {
+		g.canvas.fillRect(g.cellRect(p).Inset(1), hazardColor)
+	}
+	for _, p := range boardState.Food {
+		g.canvas.fillCircle(g.cellCenter(p), g.cellSize/3, foodColor)
+	}
+	for _, snake := range boardState.Snakes {
+		if snake.EliminatedCause != rules.NotEliminated && snake.EliminatedOnTurn != boardState.Turn {
+			continue
+		}
+		g.drawSnake(snake, options.snakeColor(snake.ID))
+	}
+}
+
+func (g grid) drawSnake(snake rules.Snake, c color.RGBA) {
+	eliminated := snake.EliminatedCause != rules.NotEliminated
+	if eliminated {
+		c = fadeColor(c)
+	}
+	margin := g.cellSize / 8
+	for i, p := range snake.Body {
+		g.canvas.fillRect(g.cellRect(p).Inset(margin), c)
+		if i == 0 {
+			continue
+		}
+		// Join each segment to the one before it, unless they're not
+		// adjacent, e.g. when the snake wraps around the board
+		prev := snake.Body[i-1]
+		dx, dy := p.X-prev.X, p.Y-prev.Y
+		if dx*dx+dy*dy != 1 {
+			continue
+		}
+		g.canvas.fillRect(g.cellRect(p).Inset(margin).Union(g.cellRect(prev).Inset(margin)), c)
+	}
+	if len(snake.Body) == 0 {
+		return
+	}
+
+	if eliminated {
+		g.canvas.drawCross(g.cellRect(snake.Body[0]).Inset(g.cellSize/4), markColor)
+		return
+	}
+	eye := g.cellSize / 5
+	center := g.cellCenter(snake.Body[0])
+	corner := center.Sub(image.Point{eye / 2, eye / 2})
+	g.canvas.fillRect(image.Rectangle{corner, corner.Add(image.Point{eye, eye})}, markColor)
+}
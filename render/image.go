@@ -0,0 +1,88 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+
+	"github.com/BattlesnakeOfficial/rules"
+)
+
+// Draw a board. Snakes eliminated on the board's turn are drawn faded and
+// crossed out, and snakes eliminated on earlier turns aren't drawn.
+func Image(boardState *rules.BoardState, options Options) *image.RGBA {
+	cellSize := options.cellSize()
+	img := image.NewRGBA(image.Rect(0, 0, boardState.Width*cellSize, boardState.Height*cellSize))
+	draw.Draw(img, img.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+
+	grid := grid{canvas: imageCanvas{img}, cellSize: cellSize, height: boardState.Height}
+	grid.drawBoard(boardState, options)
+	return img
+}
+
+// Draw each board as a frame of an animation, with a delay between frames in
+// 100ths of a second.
+func GIF(boardStates []*rules.BoardState, options Options, delay int) *gif.GIF {
+	palette := imagePalette(boardStates, options)
+	animation := &gif.GIF{}
+	for _, boardState := range boardStates {
+		img := Image(boardState, options)
+		frame := image.NewPaletted(img.Bounds(), palette)
+		// Every color that's drawn is in the palette, so there's no need to dither
+		draw.Draw(frame, frame.Bounds(), img, image.Point{}, draw.Src)
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, delay)
+	}
+	return animation
+}
+
+// Every color used to draw the boards.
+func imagePalette(boardStates []*rules.BoardState, options Options) color.Palette {
+	palette := color.Palette{backgroundColor, cellColor, hazardColor, foodColor, markColor}
+	seen := map[color.RGBA]bool{}
+	for _, c := range palette {
+		seen[c.(color.RGBA)] = true
+	}
+	for _, boardState := range boardStates {
+		for _, snake := range boardState.Snakes {
+			c := options.snakeColor(snake.ID)
+			for _, c := range []color.RGBA{c, fadeColor(c)} {
+				if !seen[c] && len(palette) < 256 {
+					seen[c] = true
+					palette = append(palette, c)
+				}
+			}
+		}
+	}
+	return palette
+}
+
+// A canvas that draws pixels on an image.
+type imageCanvas struct {
+	img draw.Image
+}
+
+func (canvas imageCanvas) fillRect(rect image.Rectangle, c color.RGBA) {
+	draw.Draw(canvas.img, rect, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func (canvas imageCanvas) fillCircle(center image.Point, radius int, c color.RGBA) {
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y <= radius*radius {
+				canvas.img.Set(center.X+x, center.Y+y, c)
+			}
+		}
+	}
+}
+
+func (canvas imageCanvas) drawCross(rect image.Rectangle, c color.RGBA) {
+	size := rect.Dx()
+	for i := 0; i < size; i++ {
+		canvas.img.Set(rect.Min.X+i, rect.Min.Y+i, c)
+		canvas.img.Set(rect.Min.X+i+1, rect.Min.Y+i, c)
+		canvas.img.Set(rect.Max.X-1-i, rect.Min.Y+i, c)
+		canvas.img.Set(rect.Max.X-2-i, rect.Min.Y+i, c)
+	}
+}
@@ -0,0 +1,97 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/stretchr/testify/require"
+)
+
+// The color at the center of a board cell.
+func cellCenter(img image.Image, boardState *rules.BoardState, p rules.Point, cellSize int) color.RGBA {
+	x := p.X*cellSize + cellSize/2
+	y := (boardState.Height-1-p.Y)*cellSize + cellSize/2
+	return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+}
+
+// The color near the corner of a board cell, away from eyes and food.
+func cellCorner(img image.Image, boardState *rules.BoardState, p rules.Point, cellSize int) color.RGBA {
+	x := p.X*cellSize + cellSize/4
+	y := (boardState.Height-1-p.Y)*cellSize + cellSize/4
+	return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+}
+
+func TestParseColor(t *testing.T) {
+	c, ok := ParseColor("#ef03d3")
+	require.True(t, ok)
+	require.Equal(t, color.RGBA{0xef, 0x03, 0xd3, 0xff}, c)
+
+	for _, hex := range []string{"", "ef03d3", "#ef03d", "#ef03dz", "red"} {
+		_, ok := ParseColor(hex)
+		require.False(t, ok, hex)
+	}
+}
+
+func TestImage(t *testing.T) {
+	boardState := rules.NewBoardState(5, 4).
+		WithTurn(3).
+		WithFood([]rules.Point{{X: 4, Y: 3}}).
+		WithHazards([]rules.Point{{X: 0, Y: 3}}).
+		WithSnakes([]rules.Snake{
+			{ID: "one", Body: []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 0}, {X: 2, Y: 0}}, Health: 100},
+			{ID: "two", Body: []rules.Point{{X: 3, Y: 2}, {X: 3, Y: 1}}, Health: 100},
+			{ID: "dead", Body: []rules.Point{{X: 0, Y: 2}}, EliminatedCause: rules.EliminatedByCollision, EliminatedOnTurn: 2},
+			{ID: "dying", Body: []rules.Point{{X: 2, Y: 3}, {X: 2, Y: 2}}, EliminatedCause: rules.EliminatedByCollision, EliminatedOnTurn: 3},
+		})
+	options := Options{
+		CellSize:    10,
+		SnakeColors: map[string]string{"one": "#ff0000", "dying": "#0000ff"},
+	}
+	img := Image(boardState, options)
+	require.Equal(t, image.Rect(0, 0, 50, 40), img.Bounds())
+
+	// y is flipped, so the top left pixel is in the cell at (0, 3)
+	require.Equal(t, backgroundColor, color.RGBAModel.Convert(img.At(0, 0)))
+	require.Equal(t, hazardColor, cellCorner(img, boardState, rules.Point{X: 0, Y: 3}, 10))
+	require.Equal(t, cellColor, cellCorner(img, boardState, rules.Point{X: 4, Y: 0}, 10))
+	require.Equal(t, foodColor, cellCenter(img, boardState, rules.Point{X: 4, Y: 3}, 10))
+
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	require.Equal(t, markColor, cellCenter(img, boardState, rules.Point{X: 1, Y: 1}, 10))
+	require.Equal(t, red, cellCorner(img, boardState, rules.Point{X: 1, Y: 1}, 10))
+	require.Equal(t, red, cellCenter(img, boardState, rules.Point{X: 2, Y: 0}, 10))
+	// Adjacent segments are joined
+	require.Equal(t, red, color.RGBAModel.Convert(img.At(15, 30)))
+	require.Equal(t, defaultSnakeColor, cellCenter(img, boardState, rules.Point{X: 3, Y: 1}, 10))
+
+	require.Equal(t, cellColor, cellCenter(img, boardState, rules.Point{X: 0, Y: 2}, 10))
+	require.Equal(t, fadeColor(color.RGBA{0, 0, 0xff, 0xff}), cellCorner(img, boardState, rules.Point{X: 2, Y: 2}, 10))
+	require.Equal(t, markColor, cellCenter(img, boardState, rules.Point{X: 2, Y: 3}, 10))
+}
+
+func TestGIF(t *testing.T) {
+	first := rules.NewBoardState(3, 3).WithSnakes([]rules.Snake{
+		{ID: "one", Body: []rules.Point{{X: 1, Y: 1}}, Health: 100},
+	})
+	second := first.Clone().WithTurn(1)
+	second.Snakes[0].Body = []rules.Point{{X: 1, Y: 2}}
+
+	options := Options{SnakeColors: map[string]string{"one": "#123456"}}
+	animation := GIF([]*rules.BoardState{first, second}, options, 20)
+	require.Len(t, animation.Image, 2)
+	require.Equal(t, []int{20, 20}, animation.Delay)
+	require.Len(t, animation.Image[0].Palette, 7)
+
+	// Frames match the images exactly
+	for i, boardState := range []*rules.BoardState{first, second} {
+		img := Image(boardState, options)
+		frame := animation.Image[i]
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			for x := 0; x < img.Bounds().Dx(); x++ {
+				require.Equal(t, img.At(x, y), color.RGBAModel.Convert(frame.At(x, y)))
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"sort"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// Extra information drawn over a board in an SVG.
+type Overlays struct {
+	// Label each column and row with its coordinate, along the bottom and left of the board
+	Coordinates bool
+	// Text to draw on cells, such as scores from a snake's search
+	Annotations map[rules.Point]string
+	// The move chosen by each snake by ID, drawn as an arrow from its head
+	Moves map[string]string
+}
+
+// Write a board as an SVG document. The board is drawn the same way as by
+// Image, with the overlays on top.
+func SVG(w io.Writer, boardState *rules.BoardState, options Options, overlays Overlays) error {
+	cellSize := options.cellSize()
+	width, height := boardState.Width*cellSize, boardState.Height*cellSize
+	var origin image.Point
+	if overlays.Coordinates {
+		// Leave a cell's space for the labels
+		origin.X = cellSize
+		width += cellSize
+		height += cellSize
+	}
+
+	canvas := &svgCanvas{}
+	fmt.Fprintf(&canvas.buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	fmt.Fprintf(&canvas.buf, `<defs><marker id="arrowhead" viewBox="0 0 10 10" refX="5" refY="5" markerWidth="4" markerHeight="4" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="%s"/></marker></defs>`+"\n", svgColor(markColor))
+	canvas.fillRect(image.Rect(0, 0, width, height), backgroundColor)
+
+	grid := grid{canvas: canvas, cellSize: cellSize, height: boardState.Height, origin: origin}
+	grid.drawBoard(boardState, options)
+
+	if overlays.Coordinates {
+		for x := 0; x < boardState.Width; x++ {
+			center := grid.cellCenter(rules.Point{X: x, Y: 0})
+			canvas.text(image.Point{center.X, center.Y + cellSize}, cellSize/2, fmt.Sprint(x))
+		}
+		for y := 0; y < boardState.Height; y++ {
+			center := grid.cellCenter(rules.Point{X: 0, Y: y})
+			canvas.text(image.Point{center.X - cellSize, center.Y}, cellSize/2, fmt.Sprint(y))
+		}
+	}
+
+	for _, snake := range boardState.Snakes {
+		move, ok := overlays.Moves[snake.ID]
+		if !ok || len(snake.Body) == 0 || snake.EliminatedCause != rules.NotEliminated {
+			continue
+		}
+		direction, ok := moveDirections[move]
+		if !ok {
+			continue
+		}
+		from := grid.cellCenter(snake.Body[0])
+		to := from.Add(direction.Mul(cellSize * 4 / 5))
+		canvas.arrow(from, to, cellSize/8)
+	}
+
+	// Sort the annotations so the output is the same each time
+	points := make([]rules.Point, 0, len(overlays.Annotations))
+	for p := range overlays.Annotations {
+		points = append(points, p)
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Y != points[j].Y {
+			return points[i].Y > points[j].Y
+		}
+		return points[i].X < points[j].X
+	})
+	for _, p := range points {
+		canvas.text(grid.cellCenter(p), cellSize*2/5, overlays.Annotations[p])
+	}
+
+	canvas.buf.WriteString("</svg>\n")
+	_, err := canvas.buf.WriteTo(w)
+	return err
+}
+
+// The direction of each move in the canvas, where y increases downwards.
+var moveDirections = map[string]image.Point{
+	rules.MoveUp:    {0, -1},
+	rules.MoveDown:  {0, 1},
+	rules.MoveLeft:  {-1, 0},
+	rules.MoveRight: {1, 0},
+}
+
+// Convert the board from a snake request, such as one in a scenario. Returns
+// the board and options with each snake's color.
+func FromClientBoard(board client.Board, options Options) (*rules.BoardState, Options) {
+	points := func(coords []client.Coord) []rules.Point {
+		result := make([]rules.Point, 0, len(coords))
+		for _, c := range coords {
+			result = append(result, rules.Point{X: c.X, Y: c.Y})
+		}
+		return result
+	}
+
+	boardState := rules.NewBoardState(board.Width, board.Height).
+		WithFood(points(board.Food)).
+		WithHazards(points(board.Hazards))
+	snakeColors := map[string]string{}
+	for id, c := range options.SnakeColors {
+		snakeColors[id] = c
+	}
+	for _, snake := range board.Snakes {
+		boardState.Snakes = append(boardState.Snakes, rules.Snake{
+			ID:     snake.ID,
+			Body:   points(snake.Body),
+			Health: snake.Health,
+		})
+		if _, ok := snakeColors[snake.ID]; !ok {
+			snakeColors[snake.ID] = snake.Customizations.Color
+		}
+	}
+	options.SnakeColors = snakeColors
+	return boardState, options
+}
+
+// A canvas that writes SVG elements.
+type svgCanvas struct {
+	buf bytes.Buffer
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func (canvas *svgCanvas) fillRect(rect image.Rectangle, c color.RGBA) {
+	fmt.Fprintf(&canvas.buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy(), svgColor(c))
+}
+
+func (canvas *svgCanvas) fillCircle(center image.Point, radius int, c color.RGBA) {
+	fmt.Fprintf(&canvas.buf, `<circle cx="%d" cy="%d" r="%d" fill="%s"/>`+"\n", center.X, center.Y, radius, svgColor(c))
+}
+
+func (canvas *svgCanvas) drawCross(rect image.Rectangle, c color.RGBA) {
+	fmt.Fprintf(&canvas.buf, `<path d="M %d %d L %d %d M %d %d L %d %d" stroke="%s" stroke-width="2"/>`+"\n",
+		rect.Min.X, rect.Min.Y, rect.Max.X, rect.Max.Y, rect.Max.X, rect.Min.Y, rect.Min.X, rect.Max.Y, svgColor(c))
+}
+
+func (canvas *svgCanvas) arrow(from, to image.Point, width int) {
+	if width < 1 {
+		width = 1
+	}
+	fmt.Fprintf(&canvas.buf, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="%d" marker-end="url(#arrowhead)"/>`+"\n",
+		from.X, from.Y, to.X, to.Y, svgColor(markColor), width)
+}
+
+// Write text centered on a point, outlined so that it can be read over anything on the board.
+func (canvas *svgCanvas) text(center image.Point, size int, text string) {
+	fmt.Fprintf(&canvas.buf, `<text x="%d" y="%d" font-family="sans-serif" font-size="%d" text-anchor="middle" dominant-baseline="central" fill="%s" stroke="%s" stroke-width="2" paint-order="stroke">`,
+		center.X, center.Y, size, svgColor(markColor), svgColor(cellColor))
+	_ = xml.EscapeText(&canvas.buf, []byte(text))
+	canvas.buf.WriteString("</text>\n")
+}
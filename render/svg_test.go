@@ -0,0 +1,140 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/stretchr/testify/require"
+)
+
+type svgElement struct {
+	Name  string
+	Attrs map[string]string
+	Text  string
+}
+
+// Parse an SVG document into a flat list of its elements.
+func parseSVG(t *testing.T, data []byte) []svgElement {
+	var elements []svgElement
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return elements
+		}
+		require.NoError(t, err)
+		switch token := token.(type) {
+		case xml.StartElement:
+			element := svgElement{Name: token.Name.Local, Attrs: map[string]string{}}
+			for _, attr := range token.Attr {
+				element.Attrs[attr.Name.Local] = attr.Value
+			}
+			elements = append(elements, element)
+		case xml.CharData:
+			if len(elements) > 0 {
+				elements[len(elements)-1].Text += strings.TrimSpace(string(token))
+			}
+		}
+	}
+}
+
+func findSVGElements(elements []svgElement, name string) []svgElement {
+	var found []svgElement
+	for _, element := range elements {
+		if element.Name == name {
+			found = append(found, element)
+		}
+	}
+	return found
+}
+
+func TestSVG(t *testing.T) {
+	boardState := rules.NewBoardState(3, 3).
+		WithFood([]rules.Point{{X: 2, Y: 2}}).
+		WithSnakes([]rules.Snake{
+			{ID: "one", Body: []rules.Point{{X: 0, Y: 0}, {X: 0, Y: 1}}, Health: 100},
+		})
+	options := Options{CellSize: 10, SnakeColors: map[string]string{"one": "#ff0000"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, SVG(&buf, boardState, options, Overlays{}))
+	elements := parseSVG(t, buf.Bytes())
+	require.Equal(t, "svg", elements[0].Name)
+	require.Equal(t, "30", elements[0].Attrs["width"])
+	require.Equal(t, "30", elements[0].Attrs["height"])
+	require.Empty(t, findSVGElements(elements, "text"))
+	require.Empty(t, findSVGElements(elements, "line"))
+
+	circles := findSVGElements(elements, "circle")
+	require.Len(t, circles, 1)
+	require.Equal(t, map[string]string{"cx": "25", "cy": "5", "r": "3", "fill": "#ff5a5f"}, circles[0].Attrs)
+
+	snakeRects := 0
+	for _, rect := range findSVGElements(elements, "rect") {
+		if rect.Attrs["fill"] == "#ff0000" {
+			snakeRects++
+		}
+	}
+	require.Equal(t, 3, snakeRects, "two segments and a joint")
+}
+
+func TestSVGOverlays(t *testing.T) {
+	boardState := rules.NewBoardState(3, 2).
+		WithSnakes([]rules.Snake{
+			{ID: "one", Body: []rules.Point{{X: 1, Y: 0}, {X: 0, Y: 0}}, Health: 100},
+			{ID: "two", Body: []rules.Point{{X: 2, Y: 1}}, Health: 100},
+			{ID: "dead", Body: []rules.Point{{X: 0, Y: 1}}, EliminatedCause: rules.EliminatedByCollision},
+		})
+	overlays := Overlays{
+		Coordinates: true,
+		Annotations: map[rules.Point]string{{X: 2, Y: 0}: "<3", {X: 0, Y: 1}: "-10"},
+		Moves:       map[string]string{"one": rules.MoveUp, "two": rules.MoveLeft, "dead": rules.MoveUp},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, SVG(&buf, boardState, Options{CellSize: 10}, overlays))
+	elements := parseSVG(t, buf.Bytes())
+	require.Equal(t, "40", elements[0].Attrs["width"])
+	require.Equal(t, "30", elements[0].Attrs["height"])
+
+	var texts []string
+	for _, text := range findSVGElements(elements, "text") {
+		texts = append(texts, text.Text)
+	}
+	require.Equal(t, []string{"0", "1", "2", "0", "1", "-10", "<3"}, texts)
+
+	// Board cells are moved right to make room for the row labels
+	lines := findSVGElements(elements, "line")
+	require.Len(t, lines, 2)
+	require.Equal(t, []string{"25", "15", "25", "7"}, []string{lines[0].Attrs["x1"], lines[0].Attrs["y1"], lines[0].Attrs["x2"], lines[0].Attrs["y2"]})
+	require.Equal(t, []string{"35", "5", "27", "5"}, []string{lines[1].Attrs["x1"], lines[1].Attrs["y1"], lines[1].Attrs["x2"], lines[1].Attrs["y2"]})
+}
+
+func TestFromClientBoard(t *testing.T) {
+	board := client.Board{
+		Width:   5,
+		Height:  7,
+		Food:    []client.Coord{{X: 1, Y: 2}},
+		Hazards: []client.Coord{{X: 0, Y: 0}, {X: 0, Y: 1}},
+		Snakes: []client.Snake{
+			{ID: "one", Health: 90, Body: []client.Coord{{X: 3, Y: 3}, {X: 3, Y: 2}}, Customizations: client.Customizations{Color: "#123456"}},
+			{ID: "two", Health: 80, Body: []client.Coord{{X: 4, Y: 4}}, Customizations: client.Customizations{Color: "#654321"}},
+		},
+	}
+	boardState, options := FromClientBoard(board, Options{CellSize: 15, SnakeColors: map[string]string{"two": "#ffffff"}})
+	require.Equal(t, 5, boardState.Width)
+	require.Equal(t, 7, boardState.Height)
+	require.Equal(t, []rules.Point{{X: 1, Y: 2}}, boardState.Food)
+	require.Equal(t, []rules.Point{{X: 0, Y: 0}, {X: 0, Y: 1}}, boardState.Hazards)
+	require.Equal(t, []rules.Snake{
+		{ID: "one", Health: 90, Body: []rules.Point{{X: 3, Y: 3}, {X: 3, Y: 2}}},
+		{ID: "two", Health: 80, Body: []rules.Point{{X: 4, Y: 4}}},
+	}, boardState.Snakes)
+	require.Equal(t, 15, options.CellSize)
+	require.Equal(t, map[string]string{"one": "#123456", "two": "#ffffff"}, options.SnakeColors)
+}
@@ -0,0 +1,90 @@
+package scenario
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// Chooses moves for a snake. Agents can be a snake running as a server, or
+// code running in the same process as the scenarios.
+type Agent interface {
+	Move(request client.SnakeRequest) (client.MoveResponse, error)
+}
+
+// An Agent implemented by a function.
+type AgentFunc func(request client.SnakeRequest) (client.MoveResponse, error)
+
+func (f AgentFunc) Move(request client.SnakeRequest) (client.MoveResponse, error) {
+	return f(request)
+}
+
+// An Agent that sends move requests to a snake's URL.
+type HTTPAgent struct {
+	URL    string
+	Client *http.Client
+}
+
+func (agent HTTPAgent) Move(request client.SnakeRequest) (client.MoveResponse, error) {
+	body, err := agent.post("move", request)
+	if err != nil {
+		return client.MoveResponse{}, err
+	}
+
+	var moveResponse client.MoveResponse
+	if err := json.Unmarshal(body, &moveResponse); err != nil {
+		return client.MoveResponse{}, fmt.Errorf("invalid move response %q: %w", body, err)
+	}
+	return moveResponse, nil
+}
+
+// Send a start request, so that the snake can set up anything it keeps for the game.
+func (agent HTTPAgent) Start(request client.SnakeRequest) error {
+	_, err := agent.post("start", request)
+	return err
+}
+
+// Send an end request, so that the snake can clean up anything it kept for the game.
+func (agent HTTPAgent) End(request client.SnakeRequest) error {
+	_, err := agent.post("end", request)
+	return err
+}
+
+// Post a request to an endpoint of the snake's URL, returning the body of a successful response.
+func (agent HTTPAgent) post(endpoint string, request client.SnakeRequest) ([]byte, error) {
+	u, err := url.ParseRequestURI(agent.URL)
+	if err != nil {
+		return nil, fmt.Errorf("URL %v is not valid: %w", agent.URL, err)
+	}
+	u.Path = path.Join(u.Path, endpoint)
+
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := agent.Client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := httpClient.Post(u.String(), "application/json", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status code %d: %q", res.StatusCode, body)
+	}
+	return body, nil
+}
@@ -0,0 +1,106 @@
+package scenario
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// The outcome of running a single scenario.
+type Result struct {
+	Scenario *Scenario
+	Move     string
+	Duration time.Duration
+	Failure  string // why the move failed the scenario
+	Error    error  // set when no move could be made
+}
+
+func (result Result) Passed() bool {
+	return result.Failure == "" && result.Error == nil
+}
+
+// Ask the agent for a move in each scenario, and check it.
+func Run(agent Agent, scenarios []*Scenario) []Result {
+	results := make([]Result, 0, len(scenarios))
+	for _, scenario := range scenarios {
+		startTime := time.Now()
+		response, err := agent.Move(scenario.Request)
+		result := Result{
+			Scenario: scenario,
+			Move:     response.Move,
+			Duration: time.Since(startTime),
+			Error:    err,
+		}
+		if err == nil {
+			result.Failure = scenario.Check(response.Move)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// Write the results as a JUnit XML report, which most CI systems can display.
+func WriteJUnit(w io.Writer, suiteName string, results []Result) error {
+	suite := junitTestSuite{Name: suiteName, Tests: len(results)}
+	var total time.Duration
+	for _, result := range results {
+		testCase := junitTestCase{
+			Name:      result.Scenario.Name,
+			ClassName: suiteName,
+			Time:      junitSeconds(result.Duration),
+		}
+		if result.Error != nil {
+			suite.Errors++
+			testCase.Error = &junitMessage{Message: result.Error.Error(), Body: result.Scenario.Path}
+		} else if result.Failure != "" {
+			suite.Failures++
+			testCase.Failure = &junitMessage{Message: result.Failure, Body: result.Scenario.Path}
+		}
+		total += result.Duration
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSeconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
@@ -0,0 +1,122 @@
+// Package scenario runs a Battlesnake against fixed board positions, and
+// checks which moves it makes.
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BattlesnakeOfficial/rules"
+	"github.com/BattlesnakeOfficial/rules/client"
+)
+
+// A board position, and the moves a snake may or may not make from it.
+type Scenario struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Request     client.SnakeRequest `json:"request"`
+	Allowed     []string            `json:"allowed,omitempty"`   // if set, the move must be one of these
+	Forbidden   []string            `json:"forbidden,omitempty"` // the move must not be one of these
+
+	// The file the scenario was loaded from, if any
+	Path string `json:"-"`
+}
+
+// Read a scenario from a JSON file. Scenarios without a name are named after the file.
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var scenario Scenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	scenario.Path = path
+	if scenario.Name == "" {
+		scenario.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := scenario.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &scenario, nil
+}
+
+// Read every .json file in a directory and its subdirectories as a scenario, sorted by path.
+func LoadDir(dir string) ([]*Scenario, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && filepath.Ext(path) == ".json" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	scenarios := make([]*Scenario, 0, len(paths))
+	for _, path := range paths {
+		scenario, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	return scenarios, nil
+}
+
+// Check that the scenario has a snake to move and at least one expectation.
+func (scenario *Scenario) Validate() error {
+	if scenario.Request.You.ID == "" {
+		return fmt.Errorf("scenario %#v doesn't have a \"you\" snake", scenario.Name)
+	}
+	if len(scenario.Allowed) == 0 && len(scenario.Forbidden) == 0 {
+		return fmt.Errorf("scenario %#v doesn't have any allowed or forbidden moves", scenario.Name)
+	}
+	for _, move := range append(append([]string{}, scenario.Allowed...), scenario.Forbidden...) {
+		if !isValidMove(move) {
+			return fmt.Errorf("scenario %#v has an invalid move %#v", scenario.Name, move)
+		}
+	}
+	return nil
+}
+
+// Returns a description of why a move fails the scenario, or an empty string if it passes.
+func (scenario *Scenario) Check(move string) string {
+	if !isValidMove(move) {
+		return fmt.Sprintf("%#v isn't a valid move", move)
+	}
+	for _, forbidden := range scenario.Forbidden {
+		if move == forbidden {
+			return fmt.Sprintf("moved %s, which is forbidden", move)
+		}
+	}
+	if len(scenario.Allowed) > 0 && !contains(scenario.Allowed, move) {
+		return fmt.Sprintf("moved %s, expected one of %s", move, strings.Join(scenario.Allowed, ", "))
+	}
+	return ""
+}
+
+func isValidMove(move string) bool {
+	return contains([]string{rules.MoveUp, rules.MoveDown, rules.MoveLeft, rules.MoveRight}, move)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
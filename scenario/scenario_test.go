@@ -0,0 +1,84 @@
+package scenario
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BattlesnakeOfficial/rules/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDir(t *testing.T) {
+	scenarios, err := LoadDir("testdata")
+	require.NoError(t, err)
+	require.Len(t, scenarios, 2)
+
+	// sorted by path, and named after the file when there's no name
+	require.Equal(t, "neck", scenarios[0].Name)
+	require.Equal(t, []string{"down"}, scenarios[0].Forbidden)
+	require.Equal(t, "avoid the corner walls", scenarios[1].Name)
+	require.Equal(t, filepath.Join("testdata", "walls", "corner.json"), scenarios[1].Path)
+	require.Equal(t, "you", scenarios[1].Request.You.ID)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{"request": {"you": {"id": "you"}}, "allowed": ["north"]}`), 0644))
+	_, err = LoadDir(dir)
+	require.ErrorContains(t, err, `invalid move "north"`)
+}
+
+func TestCheck(t *testing.T) {
+	scenario := &Scenario{Allowed: []string{"up", "left"}, Forbidden: []string{"left"}}
+	require.Equal(t, "", scenario.Check("up"))
+	require.Equal(t, "moved left, which is forbidden", scenario.Check("left"))
+	require.Equal(t, "moved down, expected one of up, left", scenario.Check("down"))
+	require.Equal(t, `"sideways" isn't a valid move`, scenario.Check("sideways"))
+}
+
+func TestRunAndWriteJUnit(t *testing.T) {
+	scenarios, err := LoadDir("testdata")
+	require.NoError(t, err)
+
+	agent := AgentFunc(func(request client.SnakeRequest) (client.MoveResponse, error) {
+		return client.MoveResponse{Move: "up"}, nil
+	})
+	results := Run(agent, scenarios)
+	require.Len(t, results, 2)
+	require.True(t, results[0].Passed())
+	require.True(t, results[1].Passed())
+
+	agent = AgentFunc(func(request client.SnakeRequest) (client.MoveResponse, error) {
+		if request.Turn == 5 {
+			return client.MoveResponse{}, errors.New("no move")
+		}
+		return client.MoveResponse{Move: "right"}, nil
+	})
+	results = Run(agent, scenarios)
+	require.EqualError(t, results[0].Error, "no move")
+	require.Equal(t, "moved right, expected one of up", results[1].Failure)
+
+	var output bytes.Buffer
+	require.NoError(t, WriteJUnit(&output, "scenarios", results))
+	require.Contains(t, output.String(), `<testsuite name="scenarios" tests="2" failures="1" errors="1"`)
+	require.Contains(t, output.String(), `<failure message="moved right, expected one of up">`)
+	require.Contains(t, output.String(), `<error message="no move">`)
+}
+
+func TestHTTPAgent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/snake/move", r.URL.Path)
+		_, _ = w.Write([]byte(`{"move": "left", "shout": "hi"}`))
+	}))
+	defer server.Close()
+
+	response, err := HTTPAgent{URL: server.URL + "/snake"}.Move(client.SnakeRequest{})
+	require.NoError(t, err)
+	require.Equal(t, client.MoveResponse{Move: "left", Shout: "hi"}, response)
+
+	_, err = HTTPAgent{URL: "not a url"}.Move(client.SnakeRequest{})
+	require.Error(t, err)
+}